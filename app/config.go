@@ -8,7 +8,6 @@ import (
 	"path/filepath"
 
 	"github.com/pkg/errors"
-	"gopkg.in/yaml.v3"
 
 	"github.com/aretext/aretext/config"
 )
@@ -16,31 +15,44 @@ import (
 //go:embed default-config.yaml
 var DefaultConfigYaml []byte
 
-// LoadOrCreateConfig loads the config file if it exists and creates a default config file otherwise.
-func LoadOrCreateConfig(forceDefaultConfig bool) (config.RuleSet, error) {
+// LoadOrCreateConfig loads the config file if it exists and creates a default config file
+// otherwise. If path is empty, it uses the default config path returned by defaultPath (the
+// first of config.yaml, config.toml, and config.json found in ~/.config/aretext, or
+// config.yaml if none exists); otherwise it loads exactly that path. Either way it dispatches
+// to a config.Loader chosen by the file extension (.yaml/.yml, .toml, or .json) so users can
+// keep their config in whichever format they prefer.
+func LoadOrCreateConfig(path string, forceDefaultConfig bool) (config.RuleSet, error) {
 	if forceDefaultConfig {
 		log.Printf("Using default config\n")
-		return unmarshalRuleSet(DefaultConfigYaml)
+		return config.Loaders["yaml"].Unmarshal(DefaultConfigYaml)
 	}
 
-	path, err := defaultPath()
-	if err != nil {
-		return nil, err
+	if path == "" {
+		var err error
+		path, err = defaultPath()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	loader := config.LoaderForPath(path)
+	if loader == nil {
+		return nil, errors.Errorf("Unsupported config file extension for '%s'", path)
 	}
 
 	log.Printf("Loading config from '%s'\n", path)
 	data, err := os.ReadFile(path)
 	if os.IsNotExist(err) {
 		log.Printf("Writing default config to '%s'\n", path)
-		if err := saveDefaultConfig(path); err != nil {
+		if err := saveDefaultConfig(path, loader); err != nil {
 			return nil, errors.Wrapf(err, fmt.Sprintf("Error writing default config to '%s'", path))
 		}
-		return unmarshalRuleSet(DefaultConfigYaml)
+		return config.Loaders["yaml"].Unmarshal(DefaultConfigYaml)
 	} else if err != nil {
 		return nil, errors.Wrapf(err, fmt.Sprintf("Error loading config from '%s'", path))
 	}
 
-	ruleSet, err := unmarshalRuleSet(data)
+	ruleSet, err := loader.Unmarshal(data)
 	if err != nil {
 		return nil, err
 	}
@@ -55,30 +67,48 @@ func LoadOrCreateConfig(forceDefaultConfig bool) (config.RuleSet, error) {
 	return ruleSet, nil
 }
 
+// defaultConfigFilenames are probed in order, in the default config directory, by defaultPath.
+// The first one found on disk is used, so a user who already keeps their config as
+// config.toml or config.json isn't ignored in favor of a nonexistent config.yaml; if none of
+// them exist, config.yaml is used so that's where the default config gets written.
+var defaultConfigFilenames = []string{"config.yaml", "config.toml", "config.json"}
+
 // defaultPath returns the path to the user's configuration file.
 func defaultPath() (string, error) {
 	homeDir, err := os.UserHomeDir()
 	if err != nil {
 		return "", errors.Wrapf(err, "os.UserHomeDir")
 	}
-	path := filepath.Join(homeDir, ".config", "aretext", "config.yaml")
-	return path, nil
-}
 
-func unmarshalRuleSet(data []byte) (config.RuleSet, error) {
-	var rules []config.Rule
-	if err := yaml.Unmarshal(data, &rules); err != nil {
-		return nil, errors.Wrapf(err, "yaml")
+	dirPath := filepath.Join(homeDir, ".config", "aretext")
+	for _, name := range defaultConfigFilenames {
+		path := filepath.Join(dirPath, name)
+		if _, err := os.Stat(path); err == nil {
+			return path, nil
+		}
 	}
-	return config.RuleSet(rules), nil
+
+	return filepath.Join(dirPath, defaultConfigFilenames[0]), nil
 }
 
-func saveDefaultConfig(path string) error {
+func saveDefaultConfig(path string, loader config.Loader) error {
 	dirPath := filepath.Dir(path)
 	if err := os.MkdirAll(dirPath, 0755); err != nil {
 		return errors.Wrapf(err, "os.MkdirAll")
 	}
-	if err := os.WriteFile(path, DefaultConfigYaml, 0644); err != nil {
+
+	data := DefaultConfigYaml
+	if loader.Format() != "yaml" {
+		ruleSet, err := config.Loaders["yaml"].Unmarshal(DefaultConfigYaml)
+		if err != nil {
+			return err
+		}
+		if data, err = loader.Marshal(ruleSet); err != nil {
+			return err
+		}
+	}
+
+	if err := os.WriteFile(path, data, 0644); err != nil {
 		return errors.Wrapf(err, "os.WriteFile")
 	}
 	return nil
@@ -0,0 +1,49 @@
+// Package assets provides the runtime assets (syntax definitions, color schemes, help text)
+// compiled into the aretext binary, so the editor is a single distributable file that doesn't
+// need a runtime/ directory installed alongside it. The embedded FileSystem is produced by
+// running `go generate ./assets`, which invokes tools/assets_generate.go to walk runtime/ and
+// emit assets_vfsdata.go; building with the "dev" tag instead serves runtime/ directly off
+// disk so asset changes don't require regenerating.
+package assets
+
+//go:generate go run ../tools/assets_generate.go
+
+import (
+	"net/http"
+	"os"
+	"path/filepath"
+
+	"github.com/pkg/errors"
+)
+
+// FileSystem, the virtual filesystem of embedded runtime assets, is declared by
+// assets_vfsdata.go (generated builds) or dev.go (the "dev" build tag).
+
+// Open returns the named asset (slash-rooted, e.g. "/syntax/go.json"), consulting the embedded
+// FileSystem first and falling back to a user override in ~/.config/aretext/<name>. This lets
+// users add a language or color scheme aretext doesn't ship without recompiling, while the
+// curated, embedded set stays the default.
+func Open(name string) (http.File, error) {
+	if f, err := FileSystem.Open(name); err == nil {
+		return f, nil
+	}
+
+	path, err := overridePath(name)
+	if err != nil {
+		return nil, errors.Wrapf(err, "resolving override path for asset %s", name)
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, errors.Wrapf(err, "opening asset %s", name)
+	}
+	return f, nil
+}
+
+func overridePath(name string) (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", errors.Wrapf(err, "os.UserHomeDir")
+	}
+	return filepath.Join(home, ".config", "aretext", filepath.FromSlash(name)), nil
+}
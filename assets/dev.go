@@ -0,0 +1,10 @@
+//go:build dev
+
+package assets
+
+import "net/http"
+
+// Under the "dev" build tag, FileSystem serves runtime/ directly off disk instead of the
+// generated assets_vfsdata.go, so editing a syntax definition or color scheme takes effect
+// immediately without rerunning `go generate`.
+var FileSystem http.FileSystem = http.Dir("runtime")
@@ -5,8 +5,13 @@ import (
 	"fmt"
 	"io/ioutil"
 	"log"
+	"net"
+	"net/http"
+	_ "net/http/pprof"
 	"os"
+	"runtime"
 	"runtime/pprof"
+	"runtime/trace"
 
 	"github.com/gdamore/tcell"
 	"github.com/wedaly/aretext/internal/app/aretext"
@@ -14,6 +19,13 @@ import (
 
 var logpath = flag.String("log", "", "log to file")
 var cpuprofile = flag.String("cpuprofile", "", "write cpu profile to file")
+var memprofile = flag.String("memprofile", "", "write heap profile to file on exit")
+var tracepath = flag.String("trace", "", "write execution trace to file")
+var blockprofile = flag.String("blockprofile", "", "write goroutine blocking profile to file on exit")
+var mutexprofile = flag.String("mutexprofile", "", "write mutex contention profile to file on exit")
+var pprofAddr = flag.String("pprof-addr", "", "serve net/http/pprof on this address (e.g. localhost:6060) for live profiling")
+var maxFileSize = flag.Int64("maxfilesize", 100*1024*1024, "maximum file size in bytes the editor will open")
+var configPath = flag.String("config", "", "path to config file (.yaml, .toml, or .json); defaults to the first of config.yaml, config.toml, or config.json found in ~/.config/aretext")
 
 func main() {
 	flag.Usage = printUsage
@@ -40,8 +52,32 @@ func main() {
 		defer pprof.StopCPUProfile()
 	}
 
+	if *blockprofile != "" {
+		runtime.SetBlockProfileRate(1)
+	}
+
+	if *mutexprofile != "" {
+		runtime.SetMutexProfileFraction(1)
+	}
+
+	if *pprofAddr != "" {
+		ln, err := net.Listen("tcp", *pprofAddr)
+		if err != nil {
+			exitWithError(err)
+		}
+		go func() {
+			log.Printf("serving net/http/pprof on %s\n", *pprofAddr)
+			log.Println(http.Serve(ln, nil))
+		}()
+	}
+
 	path := flag.Arg(0)
-	err := runEditor(path)
+	err := runEditor(path, *maxFileSize, *configPath, profileConfig{
+		memProfilePath:   *memprofile,
+		tracePath:        *tracepath,
+		blockProfilePath: *blockprofile,
+		mutexProfilePath: *mutexprofile,
+	})
 	if err != nil {
 		exitWithError(err)
 	}
@@ -53,7 +89,16 @@ func printUsage() {
 	flag.PrintDefaults()
 }
 
-func runEditor(path string) error {
+// profileConfig holds the -memprofile, -trace, -blockprofile, and -mutexprofile flag values,
+// grouped so runEditor can start/flush them around the screen's lifetime in one place.
+type profileConfig struct {
+	memProfilePath   string
+	tracePath        string
+	blockProfilePath string
+	mutexProfilePath string
+}
+
+func runEditor(path string, maxFileSize int64, configPath string, pc profileConfig) error {
 	screen, err := tcell.NewScreen()
 	if err != nil {
 		return err
@@ -64,7 +109,52 @@ func runEditor(path string) error {
 	}
 	defer screen.Fini()
 
-	editor, err := aretext.NewEditor(screen, path)
+	// Deferred below screen.Fini (and therefore run before it, since defers execute LIFO) so
+	// a clean shutdown always produces usable profiles, even though the terminal is restored
+	// to its normal state before the process exits.
+	if pc.tracePath != "" {
+		f, err := os.Create(pc.tracePath)
+		if err != nil {
+			return err
+		}
+		if err := trace.Start(f); err != nil {
+			f.Close()
+			return err
+		}
+		defer func() {
+			trace.Stop()
+			f.Close()
+		}()
+	}
+
+	if pc.memProfilePath != "" {
+		defer func() {
+			f, err := os.Create(pc.memProfilePath)
+			if err != nil {
+				log.Printf("error creating memory profile: %v\n", err)
+				return
+			}
+			defer f.Close()
+			runtime.GC() // get up-to-date statistics, matching the pprof package's own advice
+			if err := pprof.WriteHeapProfile(f); err != nil {
+				log.Printf("error writing memory profile: %v\n", err)
+			}
+		}()
+	}
+
+	if pc.blockProfilePath != "" {
+		defer writeLookupProfile("block", pc.blockProfilePath)
+	}
+
+	if pc.mutexProfilePath != "" {
+		defer writeLookupProfile("mutex", pc.mutexProfilePath)
+	}
+
+	// NewEditor opens path with text.NewTreeFromReaderWithLimit(r, maxFileSize), so a
+	// pathologically large file returns a *text.MaxBytesError here instead of OOMing, and
+	// loads its config with app.LoadOrCreateConfig(configPath, false), so configPath == ""
+	// falls back to the default config location.
+	editor, err := aretext.NewEditor(screen, path, maxFileSize, configPath)
 	if err != nil {
 		return err
 	}
@@ -73,6 +163,21 @@ func runEditor(path string) error {
 	return nil
 }
 
+// writeLookupProfile writes the named runtime/pprof profile (e.g. "block" or "mutex") to
+// path. Errors are logged rather than returned since this runs during shutdown, after
+// RunEventLoop has already returned.
+func writeLookupProfile(name, path string) {
+	f, err := os.Create(path)
+	if err != nil {
+		log.Printf("error creating %s profile: %v\n", name, err)
+		return
+	}
+	defer f.Close()
+	if err := pprof.Lookup(name).WriteTo(f, 0); err != nil {
+		log.Printf("error writing %s profile: %v\n", name, err)
+	}
+}
+
 func exitWithError(err error) {
 	fmt.Fprintf(os.Stderr, "%v\n", err)
 	os.Exit(1)
@@ -0,0 +1,104 @@
+package config
+
+import (
+	"encoding/json"
+	"path/filepath"
+	"strings"
+
+	"github.com/pelletier/go-toml/v2"
+	"github.com/pkg/errors"
+	"gopkg.in/yaml.v3"
+)
+
+// Loader unmarshals the bytes of a config file into a RuleSet and marshals a RuleSet back
+// into that same file format, so the default config can be written out in whatever format
+// the user's config path asks for.
+type Loader interface {
+	// Format is the file extension (without a leading dot) this Loader handles, e.g. "yaml".
+	Format() string
+	Unmarshal(data []byte) (RuleSet, error)
+	Marshal(rs RuleSet) ([]byte, error)
+}
+
+// Loaders are the config.Loader implementations aretext ships with, keyed by the file
+// extension (without a leading dot) each one handles.
+var Loaders = map[string]Loader{
+	"yaml": yamlLoader{},
+	"yml":  yamlLoader{},
+	"toml": tomlLoader{},
+	"json": jsonLoader{},
+}
+
+// LoaderForPath returns the Loader registered for path's file extension, or nil if the
+// extension doesn't match any supported format.
+func LoaderForPath(path string) Loader {
+	ext := strings.TrimPrefix(filepath.Ext(path), ".")
+	return Loaders[ext]
+}
+
+type yamlLoader struct{}
+
+func (yamlLoader) Format() string { return "yaml" }
+
+func (yamlLoader) Unmarshal(data []byte) (RuleSet, error) {
+	var rules []Rule
+	if err := yaml.Unmarshal(data, &rules); err != nil {
+		return nil, errors.Wrapf(err, "yaml")
+	}
+	return RuleSet(rules), nil
+}
+
+func (yamlLoader) Marshal(rs RuleSet) ([]byte, error) {
+	data, err := yaml.Marshal([]Rule(rs))
+	if err != nil {
+		return nil, errors.Wrapf(err, "yaml")
+	}
+	return data, nil
+}
+
+type tomlLoader struct{}
+
+func (tomlLoader) Format() string { return "toml" }
+
+// tomlDocument is the top-level shape of a TOML config file. Unlike YAML and JSON, TOML has
+// no syntax for an unkeyed array-of-tables as the document root, so the rules must live under
+// a named key ("rules = [[...]]" / "[[rules]]") instead of RuleSet's bare []Rule.
+type tomlDocument struct {
+	Rules []Rule `toml:"rules"`
+}
+
+func (tomlLoader) Unmarshal(data []byte) (RuleSet, error) {
+	var doc tomlDocument
+	if err := toml.Unmarshal(data, &doc); err != nil {
+		return nil, errors.Wrapf(err, "toml")
+	}
+	return RuleSet(doc.Rules), nil
+}
+
+func (tomlLoader) Marshal(rs RuleSet) ([]byte, error) {
+	data, err := toml.Marshal(tomlDocument{Rules: []Rule(rs)})
+	if err != nil {
+		return nil, errors.Wrapf(err, "toml")
+	}
+	return data, nil
+}
+
+type jsonLoader struct{}
+
+func (jsonLoader) Format() string { return "json" }
+
+func (jsonLoader) Unmarshal(data []byte) (RuleSet, error) {
+	var rules []Rule
+	if err := json.Unmarshal(data, &rules); err != nil {
+		return nil, errors.Wrapf(err, "json")
+	}
+	return RuleSet(rules), nil
+}
+
+func (jsonLoader) Marshal(rs RuleSet) ([]byte, error) {
+	data, err := json.MarshalIndent([]Rule(rs), "", "  ")
+	if err != nil {
+		return nil, errors.Wrapf(err, "json")
+	}
+	return data, nil
+}
@@ -0,0 +1,36 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoaderRoundTrip(t *testing.T) {
+	rs := RuleSet{
+		{
+			Name:    "go",
+			Pattern: "**/*.go",
+			Config: map[string]interface{}{
+				"tabSize":   2,
+				"tabExpand": true,
+			},
+		},
+	}
+
+	for format, loader := range Loaders {
+		t.Run(format, func(t *testing.T) {
+			data, err := loader.Marshal(rs)
+			require.NoError(t, err)
+
+			got, err := loader.Unmarshal(data)
+			require.NoError(t, err)
+			require.Len(t, got, 1)
+
+			c := got.ConfigForPath("main.go")
+			assert.Equal(t, 2, c.TabSize)
+			assert.Equal(t, true, c.TabExpand)
+		})
+	}
+}
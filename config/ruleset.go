@@ -0,0 +1,167 @@
+package config
+
+import (
+	"path/filepath"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// Defaults applied before any Rule in a RuleSet is considered.
+const (
+	DefaultSyntaxLanguage = ""
+	DefaultTabSize        = 4
+	DefaultTabExpand      = false
+	DefaultAutoIndent     = false
+	DefaultFormatOnSave   = ""
+	DefaultRestoreCursor  = false
+)
+
+// MenuCommandConfig configures a user-defined entry in the command menu.
+type MenuCommandConfig struct {
+	Name string `yaml:"name" toml:"name" json:"name"`
+	Save bool   `yaml:"save" toml:"save" json:"save"`
+	Mode string `yaml:"mode" toml:"mode" json:"mode"`
+	Args []string `yaml:"args" toml:"args" json:"args"`
+}
+
+// Config holds the effective settings for a single document, after applying any RuleSet
+// rules that match its path.
+type Config struct {
+	SyntaxLanguage string
+	TabSize        int
+	TabExpand      bool
+	AutoIndent     bool
+	MenuCommands   []MenuCommandConfig
+
+	// FormatOnSave, if non-empty, is an external command (e.g. "gofmt") that the save path
+	// pipes the buffer through before writing it, via input.RunFilterCommand.
+	FormatOnSave string
+
+	// RestoreCursor enables consulting the session package's Store on file open to restore
+	// the cursor position, selection anchor, and viewport top line from the last time this
+	// file was edited.
+	RestoreCursor bool
+}
+
+// KeyBindingConfig declares one user-configurable keybinding. Action names are resolved
+// against the input package's action registry (input.Actions and input.ActionBuilders).
+// Exactly one of Action or Actions should be set: Action for a single named action, Actions
+// for a sequence of them to run as one chained action (see input.Chain). Args carries any
+// extra parameters a builder-backed action needs.
+type KeyBindingConfig struct {
+	Mode    string                 `yaml:"mode" toml:"mode" json:"mode"`
+	Key     string                 `yaml:"key" toml:"key" json:"key"`
+	Action  string                 `yaml:"action" toml:"action" json:"action"`
+	Actions []string               `yaml:"actions" toml:"actions" json:"actions"`
+	Args    map[string]interface{} `yaml:"args" toml:"args" json:"args"`
+}
+
+// Rule overrides Config fields, and adds or rebinds keybindings, for documents whose path
+// matches Pattern.
+// Config is a loosely-typed map (rather than a Config struct) because a rule may set only a
+// handful of fields, and because this is the shape that falls naturally out of unmarshaling
+// a config file without requiring every field to be a pointer.
+type Rule struct {
+	Name        string                 `yaml:"name" toml:"name" json:"name"`
+	Pattern     string                 `yaml:"pattern" toml:"pattern" json:"pattern"`
+	Config      map[string]interface{} `yaml:"config" toml:"config" json:"config"`
+	KeyBindings []KeyBindingConfig     `yaml:"keyBindings" toml:"keyBindings" json:"keyBindings"`
+}
+
+// RuleSet is an ordered list of Rules, as loaded from a config file.
+type RuleSet []Rule
+
+// ConfigForPath returns the effective Config for a document at path, applying every Rule in
+// the set (in the order they appear) whose Pattern matches path on top of the defaults.
+func (rs RuleSet) ConfigForPath(path string) Config {
+	c := Config{
+		SyntaxLanguage: DefaultSyntaxLanguage,
+		TabSize:        DefaultTabSize,
+		TabExpand:      DefaultTabExpand,
+		AutoIndent:     DefaultAutoIndent,
+		MenuCommands:   []MenuCommandConfig{},
+		FormatOnSave:   DefaultFormatOnSave,
+		RestoreCursor:  DefaultRestoreCursor,
+	}
+
+	for _, rule := range rs {
+		if patternMatchesPath(rule.Pattern, path) {
+			applyRuleConfig(&c, rule.Config)
+		}
+	}
+
+	return c
+}
+
+// Validate reports an error if any rule's pattern is malformed.
+func (rs RuleSet) Validate() error {
+	for _, rule := range rs {
+		if _, err := filepath.Match(trimDoubleStarPrefix(rule.Pattern), ""); err != nil {
+			return errors.Wrapf(err, "rule %q has an invalid pattern %q", rule.Name, rule.Pattern)
+		}
+	}
+	return nil
+}
+
+// patternMatchesPath reports whether path matches pattern. Patterns follow the conventional
+// "**/*.ext" shorthand for "match anywhere in the directory tree, regardless of depth"; a
+// pattern without that prefix is matched against the path exactly.
+func patternMatchesPath(pattern, path string) bool {
+	if rest, hadPrefix := cutDoubleStarPrefix(pattern); hadPrefix {
+		matched, err := filepath.Match(rest, filepath.Base(path))
+		return err == nil && matched
+	}
+	matched, err := filepath.Match(pattern, path)
+	return err == nil && matched
+}
+
+func cutDoubleStarPrefix(pattern string) (rest string, ok bool) {
+	const prefix = "**/"
+	if !strings.HasPrefix(pattern, prefix) {
+		return pattern, false
+	}
+	return strings.TrimPrefix(pattern, prefix), true
+}
+
+func trimDoubleStarPrefix(pattern string) string {
+	rest, _ := cutDoubleStarPrefix(pattern)
+	return rest
+}
+
+func applyRuleConfig(c *Config, overrides map[string]interface{}) {
+	if v, ok := overrides["syntaxLanguage"].(string); ok {
+		c.SyntaxLanguage = v
+	}
+	if v, ok := intFromOverride(overrides["tabSize"]); ok {
+		c.TabSize = v
+	}
+	if v, ok := overrides["tabExpand"].(bool); ok {
+		c.TabExpand = v
+	}
+	if v, ok := overrides["autoIndent"].(bool); ok {
+		c.AutoIndent = v
+	}
+	if v, ok := overrides["formatOnSave"].(string); ok {
+		c.FormatOnSave = v
+	}
+	if v, ok := overrides["restoreCursor"].(bool); ok {
+		c.RestoreCursor = v
+	}
+}
+
+// intFromOverride converts an integer-valued rule config field to an int, regardless of which
+// loader produced it: encoding/json unmarshals numbers as float64, go-toml/v2 unmarshals
+// integers as int64, and yaml.v3 unmarshals them as int.
+func intFromOverride(v interface{}) (int, bool) {
+	switch n := v.(type) {
+	case int:
+		return n, true
+	case int64:
+		return int(n), true
+	case float64:
+		return int(n), true
+	default:
+		return 0, false
+	}
+}
@@ -16,7 +16,14 @@ import (
 // DrawBuffer draws text buffer in the screen.
 func DrawBuffer(screen tcell.Screen, palette *Palette, buffer *state.BufferState, inputMode state.InputMode) {
 	x, y, width, height := viewDimensions(buffer)
-	sr := NewScreenRegion(screen, x, y, width, height)
+
+	minimapWidth := 0
+	if buffer.MinimapEnabled() {
+		minimapWidth = 1
+	}
+	textWidth := width - minimapWidth
+
+	sr := NewScreenRegion(screen, x, y, textWidth, height)
 	textTree := buffer.TextTree()
 	cursorPos := buffer.CursorPosition()
 	selectedRegion := buffer.SelectedRegion()
@@ -26,9 +33,21 @@ func DrawBuffer(screen tcell.Screen, palette *Palette, buffer *state.BufferState
 	showSpaces := buffer.ShowSpaces()
 	lineNumMargin := buffer.LineNumMarginWidth() // Zero if line numbers disabled.
 	wrapConfig := buffer.LineWrapConfig()
+	maxLineWidth := int(wrapConfig.MaxLineWidth)
+
+	colOffset := uint64(0)
+	if buffer.HorizontalScrollEnabled() {
+		cursorLineNum := textTree.LineNumForPosition(cursorPos)
+		cursorLineStart := textTree.LineStartPosition(cursorLineNum)
+		cursorCol := cursorColumnInLine(textTree, cursorLineStart, cursorPos, wrapConfig)
+		colOffset = updatedColOffset(buffer.ViewTextColOffset(), cursorCol, maxLineWidth)
+		state.SetViewTextColOffset(buffer, colOffset)
+	}
+
 	wrappedLineIter := segment.NewWrappedLineIter(wrapConfig, textTree, pos)
 	wrappedLine := segment.Empty()
 	searchMatch := buffer.SearchMatch()
+	transformer := TransformerForName(buffer.RuneTransformName())
 
 	sr.HideCursor()
 
@@ -49,7 +68,7 @@ func DrawBuffer(screen tcell.Screen, palette *Palette, buffer *state.BufferState
 			inputMode,
 			pos,
 			row,
-			int(wrapConfig.MaxLineWidth),
+			maxLineWidth,
 			lineNum,
 			lineNumMargin,
 			lineStartPos,
@@ -61,6 +80,8 @@ func DrawBuffer(screen tcell.Screen, palette *Palette, buffer *state.BufferState
 			wrapConfig.WidthFunc,
 			showTabs,
 			showSpaces,
+			transformer,
+			colOffset,
 		)
 		pos += wrappedLine.NumRunes()
 	}
@@ -70,6 +91,57 @@ func DrawBuffer(screen tcell.Screen, palette *Palette, buffer *state.BufferState
 		showCursorInBuffer(sr, int(lineNumMargin), 0, palette, inputMode)
 		drawLineNumIfNecessary(sr, palette, 0, 0, lineNumMargin)
 	}
+
+	if minimapWidth > 0 {
+		drawMinimap(screen, palette, buffer, x+textWidth, y, minimapWidth, height)
+	}
+}
+
+// cursorColumnInLine returns cursorPos's grapheme-cluster-width column within its line
+// (starting at lineStartPos), ignoring soft-wrap, so horizontal scroll can follow the cursor
+// even past whatever column a wrapped render would have broken the line at.
+func cursorColumnInLine(textTree *state.TextTree, lineStartPos uint64, cursorPos uint64, wrapConfig segment.LineWrapConfig) uint64 {
+	unwrapped := wrapConfig
+	unwrapped.MaxLineWidth = 1 << 30
+	iter := segment.NewWrappedLineIter(unwrapped, textTree, lineStartPos)
+	line := segment.Empty()
+	if err := iter.NextSegment(line); err != nil {
+		return 0
+	}
+	lineRunes := line.Runes()
+
+	var gcBreaker segment.GraphemeClusterBreaker
+	gcRunes := []rune{'\x00', '\x00', '\x00', '\x00'}[:0]
+	var width uint64
+	pos := lineStartPos
+	var i int
+	for i < len(lineRunes) && pos < cursorPos {
+		for _, r := range lineRunes[i:] {
+			canBreakBefore := gcBreaker.ProcessRune(r)
+			if canBreakBefore && len(gcRunes) > 0 {
+				break
+			}
+			gcRunes = append(gcRunes, r)
+		}
+		width += wrapConfig.WidthFunc(gcRunes, width)
+		i += len(gcRunes)
+		pos += uint64(len(gcRunes))
+		gcRunes = gcRunes[:0]
+	}
+	return width
+}
+
+// updatedColOffset adjusts currentOffset just enough to bring cursorCol back within
+// [offset, offset+maxLineWidth), the same way vertical scrolling keeps the cursor's line
+// within [ViewOrigin, ViewOrigin+height).
+func updatedColOffset(currentOffset uint64, cursorCol uint64, maxLineWidth int) uint64 {
+	if cursorCol < currentOffset {
+		return cursorCol
+	}
+	if maxLineWidth > 0 && cursorCol >= currentOffset+uint64(maxLineWidth) {
+		return cursorCol - uint64(maxLineWidth) + 1
+	}
+	return currentOffset
 }
 
 func viewDimensions(buffer *state.BufferState) (int, int, int, int) {
@@ -96,6 +168,8 @@ func drawLineAndSetCursor(
 	gcWidthFunc segment.GraphemeClusterWidthFunc,
 	showTabs bool,
 	showSpaces bool,
+	transformer RuneTransformer,
+	colOffset uint64,
 ) {
 	startPos := pos
 	gcRunes := []rune{'\x00', '\x00', '\x00', '\x00'}[:0] // Stack-allocate runes for the last grapheme cluster.
@@ -119,11 +193,22 @@ func drawLineAndSetCursor(
 			lastGcWasNewline = (r == '\n')
 			gcRunes = append(gcRunes, r)
 		}
-		gcWidth := gcWidthFunc(gcRunes, totalWidth)
+		transformedRunes := transformer.Transform(gcRunes)
+		gcWidth := gcWidthFunc(transformedRunes, totalWidth)
 		totalWidth += gcWidth
 
-		if totalWidth > uint64(maxLineWidth) {
-			// If there isn't enough space to show the line, skip it.
+		if totalWidth <= colOffset {
+			// This grapheme cluster is scrolled off the left edge. Skip drawing it (and
+			// advancing col) entirely, rather than the rune-offset skip a naive
+			// horizontal scroll would use, so wide and zero-width clusters aren't split.
+			i += len(gcRunes)
+			pos += uint64(len(gcRunes))
+			gcRunes = gcRunes[:0]
+			continue
+		}
+
+		if totalWidth-colOffset > uint64(maxLineWidth) {
+			// If there isn't enough space to show the rest of the line, skip it.
 			return
 		}
 
@@ -145,7 +230,7 @@ func drawLineAndSetCursor(
 			}
 		}
 
-		drawGraphemeCluster(sr, col, row, gcRunes, int(gcWidth), style, showTabs, showSpaces)
+		drawGraphemeCluster(sr, col, row, transformedRunes, int(gcWidth), style, showTabs, showSpaces)
 
 		if pos-startPos == uint64(maxLineWidth) {
 			// This occurs when there are maxLineWidth characters followed by a line feed.
@@ -0,0 +1,129 @@
+package display
+
+import (
+	"github.com/gdamore/tcell/v2"
+
+	"github.com/aretext/aretext/state"
+)
+
+// SplitAxis is the direction along which a Layout node's two children are arranged.
+type SplitAxis int
+
+const (
+	// SplitHorizontal stacks children top-to-bottom, divided by a horizontal border.
+	SplitHorizontal = SplitAxis(iota)
+	// SplitVertical arranges children left-to-right, divided by a vertical border.
+	SplitVertical
+)
+
+// minPaneSize is the smallest width or height, in cells, a split child is allowed to shrink to.
+// Below this a pane can no longer show a cursor and a line number margin, so drags and new
+// splits are clamped instead of producing an unusable pane.
+const minPaneSize = 2
+
+// Layout is a binary tree of split viewports. A leaf holds the buffer to draw; an interior
+// node arranges its two children along Axis, with FirstWeight giving FirstChild's fraction of
+// the parent's space along that axis and SecondChild taking the remainder.
+type Layout struct {
+	Buffer *state.BufferState
+
+	Axis        SplitAxis
+	FirstChild  *Layout
+	SecondChild *Layout
+	FirstWeight float64
+}
+
+// NewLeafLayout returns a Layout that draws buffer directly, with no further splits.
+func NewLeafLayout(buffer *state.BufferState) *Layout {
+	return &Layout{Buffer: buffer}
+}
+
+// NewSplitLayout returns a Layout arranging first and second along axis, giving first
+// firstWeight of the parent's space along that axis (clamped to [0.1, 0.9]) and second the
+// remainder.
+func NewSplitLayout(axis SplitAxis, first, second *Layout, firstWeight float64) *Layout {
+	if firstWeight < 0.1 {
+		firstWeight = 0.1
+	} else if firstWeight > 0.9 {
+		firstWeight = 0.9
+	}
+	return &Layout{
+		Axis:        axis,
+		FirstChild:  first,
+		SecondChild: second,
+		FirstWeight: firstWeight,
+	}
+}
+
+// IsLeaf reports whether this node draws a buffer directly rather than arranging two children.
+func (l *Layout) IsLeaf() bool {
+	return l.FirstChild == nil && l.SecondChild == nil
+}
+
+// Leaves returns every buffer leaf in the tree, in left-to-right / top-to-bottom order, so
+// callers can cycle focus between panes with NextPane or enumerate buffers to close or save.
+func (l *Layout) Leaves() []*Layout {
+	if l.IsLeaf() {
+		return []*Layout{l}
+	}
+	leaves := l.FirstChild.Leaves()
+	return append(leaves, l.SecondChild.Leaves()...)
+}
+
+// DrawLayout draws every buffer leaf in layout within the screen region [x, y, width, height],
+// recursing through interior nodes and drawing a one-cell border between each pair of children.
+func DrawLayout(screen tcell.Screen, palette *Palette, layout *Layout, inputMode state.InputMode, x, y, width, height int) {
+	if layout.IsLeaf() {
+		setViewDimensions(layout.Buffer, x, y, width, height)
+		DrawBuffer(screen, palette, layout.Buffer, inputMode)
+		return
+	}
+
+	if layout.Axis == SplitVertical {
+		firstWidth := splitSize(width-1, layout.FirstWeight)
+		DrawLayout(screen, palette, layout.FirstChild, inputMode, x, y, firstWidth, height)
+		drawVerticalBorder(screen, palette, x+firstWidth, y, height)
+		DrawLayout(screen, palette, layout.SecondChild, inputMode, x+firstWidth+1, y, width-firstWidth-1, height)
+	} else {
+		firstHeight := splitSize(height-1, layout.FirstWeight)
+		DrawLayout(screen, palette, layout.FirstChild, inputMode, x, y, width, firstHeight)
+		drawHorizontalBorder(screen, palette, x, y+firstHeight, width)
+		DrawLayout(screen, palette, layout.SecondChild, inputMode, x, y+firstHeight+1, width, height-firstHeight-1)
+	}
+}
+
+// setViewDimensions positions and sizes buffer's viewport to the region a Layout leaf was
+// assigned, so the next DrawBuffer call (and buffer's own scrolling logic) operates on the
+// pane's actual space instead of the whole screen.
+func setViewDimensions(buffer *state.BufferState, x, y, width, height int) {
+	state.SetViewOrigin(buffer, uint64(x), uint64(y))
+	state.SetViewSize(buffer, uint64(width), uint64(height))
+}
+
+func splitSize(total int, weight float64) int {
+	size := int(float64(total)*weight + 0.5)
+	if size < minPaneSize {
+		size = minPaneSize
+	}
+	if max := total - minPaneSize; size > max {
+		size = max
+	}
+	if size < 0 {
+		size = 0
+	}
+	return size
+}
+
+func drawVerticalBorder(screen tcell.Screen, palette *Palette, col, y, height int) {
+	style := palette.StyleForBorder()
+	for row := y; row < y+height; row++ {
+		screen.SetContent(col, row, tcell.RuneVLine, nil, style)
+	}
+}
+
+func drawHorizontalBorder(screen tcell.Screen, palette *Palette, x, row, width int) {
+	style := palette.StyleForBorder()
+	for col := x; col < x+width; col++ {
+		screen.SetContent(col, row, tcell.RuneHLine, nil, style)
+	}
+}
@@ -0,0 +1,84 @@
+package display
+
+import (
+	"github.com/gdamore/tcell/v2"
+
+	"github.com/aretext/aretext/state"
+	"github.com/aretext/aretext/syntax/parser"
+)
+
+// minimapGlyphs are Unicode block elements of increasing height, used to render each minimap
+// row's token density as a single character.
+var minimapGlyphs = []rune{'▁', '▂', '▃', '▄', '▅', '▆', '▇', '█'}
+
+// drawMinimap renders a downscaled view of the whole buffer into the screen region
+// [col, y, width, height]: each row represents an equal-sized band of source lines, shaded by
+// how much of that band syntax highlighting covers, so a user can see where the dense/sparse
+// parts of a large file are without scrolling to them.
+func drawMinimap(screen tcell.Screen, palette *Palette, buffer *state.BufferState, col, y, width, height int) {
+	if height <= 0 {
+		return
+	}
+
+	textTree := buffer.TextTree()
+	numLines := textTree.NumLines()
+	if numLines == 0 {
+		return
+	}
+
+	linesPerRow := (numLines + uint64(height) - 1) / uint64(height)
+	style := palette.StyleForMinimap()
+
+	for row := 0; row < height; row++ {
+		startLine := uint64(row) * linesPerRow
+		if startLine >= numLines {
+			break
+		}
+		endLine := startLine + linesPerRow
+		if endLine > numLines {
+			endLine = numLines
+		}
+
+		startPos := textTree.LineStartPosition(startLine)
+		endPos := textTree.LineStartPosition(endLine)
+		tokens := buffer.SyntaxTokensIntersectingRange(startPos, endPos)
+		glyph := minimapGlyphForDensity(tokenDensity(tokens, startPos, endPos))
+
+		for c := col; c < col+width; c++ {
+			screen.SetContent(c, y+row, glyph, nil, style)
+		}
+	}
+}
+
+// tokenDensity returns the fraction of [startPos, endPos) that syntax tokens cover.
+func tokenDensity(tokens []parser.Token, startPos, endPos uint64) float64 {
+	span := endPos - startPos
+	if span == 0 {
+		return 0
+	}
+
+	var covered uint64
+	for _, token := range tokens {
+		tokenStart, tokenEnd := token.StartPos, token.EndPos
+		if tokenStart < startPos {
+			tokenStart = startPos
+		}
+		if tokenEnd > endPos {
+			tokenEnd = endPos
+		}
+		if tokenEnd > tokenStart {
+			covered += tokenEnd - tokenStart
+		}
+	}
+	return float64(covered) / float64(span)
+}
+
+func minimapGlyphForDensity(density float64) rune {
+	idx := int(density * float64(len(minimapGlyphs)-1))
+	if idx < 0 {
+		idx = 0
+	} else if idx >= len(minimapGlyphs) {
+		idx = len(minimapGlyphs) - 1
+	}
+	return minimapGlyphs[idx]
+}
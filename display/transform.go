@@ -0,0 +1,78 @@
+package display
+
+import "unicode"
+
+// RuneTransformer rewrites one grapheme cluster's runes before it's measured and drawn, so
+// alternate output devices (single-case braille displays, ASCII-only terminals) can represent
+// text they otherwise couldn't. A transformer may emit a different number of runes than it was
+// given; drawLineAndSetCursor re-measures the output with gcWidthFunc, so column/cursor
+// positioning stays correct regardless of how much a transform expands or shrinks a cluster.
+type RuneTransformer interface {
+	Transform(gcRunes []rune) []rune
+}
+
+// NoRuneTransform leaves every grapheme cluster unchanged. It's the default when a buffer
+// hasn't selected a display transform.
+var NoRuneTransform RuneTransformer = noRuneTransform{}
+
+type noRuneTransform struct{}
+
+func (noRuneTransform) Transform(gcRunes []rune) []rune {
+	return gcRunes
+}
+
+// CanuteRuneTransform prefixes every uppercase letter with a tilde, so single-case braille
+// displays (such as the Canute 360) that render everything in one case can still signal
+// capitalization.
+var CanuteRuneTransform RuneTransformer = canuteRuneTransform{}
+
+type canuteRuneTransform struct{}
+
+func (canuteRuneTransform) Transform(gcRunes []rune) []rune {
+	if len(gcRunes) == 0 || !unicode.IsUpper(gcRunes[0]) {
+		return gcRunes
+	}
+	out := make([]rune, 0, len(gcRunes)+1)
+	out = append(out, '~')
+	return append(out, gcRunes...)
+}
+
+// fullWidthOffset is the fixed distance between a fullwidth Unicode form (U+FF01-U+FF5E) and
+// its ASCII equivalent (U+0021-U+007E).
+const fullWidthOffset = 0xFEE0
+
+// ASCIIRuneTransform substitutes fullwidth forms (U+FF01-U+FF5E) and the ideographic space
+// with their ASCII equivalents, so terminals that can't render (or misrender the width of)
+// those runes don't throw off column alignment. Other ambiguous-width runes are left unchanged.
+var ASCIIRuneTransform RuneTransformer = asciiRuneTransform{}
+
+type asciiRuneTransform struct{}
+
+func (asciiRuneTransform) Transform(gcRunes []rune) []rune {
+	out := make([]rune, len(gcRunes))
+	for i, r := range gcRunes {
+		switch {
+		case r >= 0xFF01 && r <= 0xFF5E:
+			out[i] = r - fullWidthOffset
+		case r == '　': // ideographic space
+			out[i] = ' '
+		default:
+			out[i] = r
+		}
+	}
+	return out
+}
+
+// TransformerForName resolves the name a buffer selected (via "set display transform ..." or
+// config) to the RuneTransformer it names. An unrecognized or empty name falls back to
+// NoRuneTransform.
+func TransformerForName(name string) RuneTransformer {
+	switch name {
+	case "canute":
+		return CanuteRuneTransform
+	case "ascii":
+		return ASCIIRuneTransform
+	default:
+		return NoRuneTransform
+	}
+}
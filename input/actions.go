@@ -7,6 +7,7 @@ import (
 
 	"github.com/aretext/aretext/clipboard"
 	"github.com/aretext/aretext/locate"
+	"github.com/aretext/aretext/plugin"
 	"github.com/aretext/aretext/selection"
 	"github.com/aretext/aretext/state"
 	"github.com/aretext/aretext/text"
@@ -18,6 +19,19 @@ type Action func(*state.EditorState)
 // EmptyAction is an action that does nothing.
 func EmptyAction(s *state.EditorState) {}
 
+// Chain combines multiple actions into one, applying each in order to the same
+// *state.EditorState. The whole sequence runs under a single undo checkpoint, so undoing a
+// chained binding reverts every action in it as one step, the same way ReturnToNormalModeAfterInsert
+// already composes several state mutations into what the user perceives as a single edit.
+func Chain(actions ...Action) Action {
+	return func(s *state.EditorState) {
+		state.CheckpointUndo(s)
+		for _, action := range actions {
+			action(s)
+		}
+	}
+}
+
 func countArgOrDefault(countArg *uint64, defaultCount uint64) uint64 {
 	if countArg != nil {
 		return *countArg
@@ -474,10 +488,10 @@ func PasteBeforeCursor(s *state.EditorState) {
 	state.PasteBeforeCursor(s, clipboard.PageDefault)
 }
 
-func ShowCommandMenu(config Config) Action {
+func ShowCommandMenu(config Config, manager *plugin.Manager) Action {
 	return func(s *state.EditorState) {
 		// This sets the input mode to menu.
-		state.ShowMenu(s, state.MenuStyleCommand, commandMenuItems(config))
+		state.ShowMenu(s, state.MenuStyleCommand, commandMenuItems(config, manager))
 	}
 }
 
@@ -591,3 +605,49 @@ func CopySelectionAndReturnToNormalMode(s *state.EditorState) {
 	state.CopySelection(s)
 	ReturnToNormalMode(s)
 }
+
+// ClearSessionForFile removes the persisted cursor position, selection anchor, search query,
+// and viewport top line that the session package stores for the current file, so the next
+// time it's opened starts fresh instead of restoring a (possibly now-stale) position.
+func ClearSessionForFile(s *state.EditorState) {
+	state.ClearSessionForFile(s)
+}
+
+// DuplicateLine copies the current line (or, in visual-line mode, the selected lines) and
+// pastes the copy immediately below, leaving the cursor on the new copy.
+func DuplicateLine(s *state.EditorState) {
+	state.DuplicateLine(s)
+}
+
+// DeleteWordLeft deletes from the cursor back to the start of the previous word, as a single
+// undoable edit. It's the insert-mode counterpart of DeleteAWord, usable bound to Ctrl-W.
+func DeleteWordLeft(s *state.EditorState) {
+	state.DeleteRunes(s, func(params state.LocatorParams) uint64 {
+		return locate.PrevWordStart(params.TextTree, params.TokenTree, params.CursorPos)
+	})
+}
+
+// DeleteWordRight deletes from the cursor forward to the end of the next word, as a single
+// undoable edit. Usable bound to Ctrl-Delete.
+func DeleteWordRight(s *state.EditorState) {
+	state.DeleteRunes(s, func(params state.LocatorParams) uint64 {
+		return locate.NextWordEnd(params.TextTree, params.TokenTree, params.CursorPos)
+	})
+}
+
+// MoveLinesUp swaps the current line (or, in visual-line mode, the selected lines) with the
+// line above, preserving the cursor's column.
+func MoveLinesUp(s *state.EditorState) {
+	state.MoveLinesUp(s)
+}
+
+// MoveLinesDown swaps the current line (or, in visual-line mode, the selected lines) with the
+// line below, preserving the cursor's column.
+func MoveLinesDown(s *state.EditorState) {
+	state.MoveLinesDown(s)
+}
+
+// TransposeChars swaps the two characters straddling the cursor, matching Emacs's C-t.
+func TransposeChars(s *state.EditorState) {
+	state.TransposeChars(s)
+}
@@ -7,13 +7,16 @@ import (
 	"github.com/aretext/aretext/exec"
 	"github.com/aretext/aretext/file"
 	"github.com/aretext/aretext/menu"
+	"github.com/aretext/aretext/plugin"
 	"github.com/aretext/aretext/syntax"
 	"github.com/pkg/errors"
 )
 
-func commandMenuItems(config Config) func() []menu.Item {
+// commandMenuItems returns the built-in command menu items plus, when manager is non-nil,
+// every command a loaded plugin registered and the built-in plugin manager commands.
+func commandMenuItems(config Config, manager *plugin.Manager) func() []menu.Item {
 	return func() []menu.Item {
-		return []menu.Item{
+		items := []menu.Item{
 			{
 				Name:   "quit",
 				Action: exec.NewAbortIfUnsavedChangesMutator(exec.NewQuitMutator(), true),
@@ -34,6 +37,26 @@ func commandMenuItems(config Config) func() []menu.Item {
 				Name:   "force reload",
 				Action: exec.NewReloadDocumentMutator(true),
 			},
+			{
+				Name:   "clear session",
+				Action: exec.NewClearSessionForFileMutator(),
+			},
+			{
+				Name:   "split horizontal",
+				Action: exec.NewSplitPaneMutator(exec.SplitHorizontal),
+			},
+			{
+				Name:   "split vertical",
+				Action: exec.NewSplitPaneMutator(exec.SplitVertical),
+			},
+			{
+				Name:   "close pane",
+				Action: exec.NewClosePaneMutator(),
+			},
+			{
+				Name:   "next pane",
+				Action: exec.NewNextPaneMutator(),
+			},
 			{
 				Name:   "find and open",
 				Action: exec.NewAbortIfUnsavedChangesMutator(ShowFileMenuMutator(config), true),
@@ -50,7 +73,36 @@ func commandMenuItems(config Config) func() []menu.Item {
 				Name:   "set syntax none",
 				Action: exec.NewSetSyntaxMutator(syntax.LanguageUndefined),
 			},
+			{
+				Name:   "set display transform canute",
+				Action: exec.NewSetRuneTransformMutator("canute"),
+			},
+			{
+				Name:   "set display transform ascii",
+				Action: exec.NewSetRuneTransformMutator("ascii"),
+			},
+			{
+				Name:   "set display transform none",
+				Action: exec.NewSetRuneTransformMutator(""),
+			},
+			{
+				Name:   "toggle minimap",
+				Action: exec.NewToggleMinimapMutator(),
+			},
+			{
+				Name:   "toggle horizontal scroll",
+				Action: exec.NewToggleHorizontalScrollMutator(),
+			},
+		}
+
+		if manager != nil {
+			for _, command := range manager.Commands() {
+				items = append(items, menu.Item{Name: command.Name, Action: command.Action})
+			}
+			items = append(items, PluginManagerMenuItems(manager)...)
 		}
+
+		return items
 	}
 }
 
@@ -0,0 +1,107 @@
+package input
+
+import (
+	"bytes"
+	osexec "os/exec"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/pkg/errors"
+
+	"github.com/aretext/aretext/locate"
+	"github.com/aretext/aretext/state"
+)
+
+// FilterScope selects which part of the document RunFilterCommand pipes through an external
+// command.
+type FilterScope int
+
+const (
+	// ScopeBuffer pipes the entire document through the filter command.
+	ScopeBuffer FilterScope = iota
+	// ScopeLine pipes only the line under the cursor through the filter command.
+	ScopeLine
+	// ScopeSelection pipes the current visual-mode selection through the filter command.
+	ScopeSelection
+)
+
+// RunFilterCommand runs cmd (via the shell, so pipes/args in cmd work as expected) as an
+// external process, piping the text named by scope into its stdin and replacing that same
+// region with the command's stdout, as a single undoable edit. This is how formatOnSave and
+// bindings like gofmt-on-demand are implemented: cmd reads source on stdin and writes
+// formatted source to stdout, the same contract gofmt/goimports/prettier all follow.
+//
+// A non-zero exit, a failure to start cmd, or an I/O error leaves the buffer untouched; the
+// error - including any stderr output - is reported in the status bar instead.
+func RunFilterCommand(cmd string, scope FilterScope) Action {
+	return func(s *state.EditorState) {
+		startPos, endPos, err := filterScopeRegion(s, scope)
+		if err != nil {
+			reportFilterError(s, cmd, err)
+			return
+		}
+
+		output, err := runShellFilter(cmd, state.TextInRange(s, startPos, endPos))
+		if err != nil {
+			reportFilterError(s, cmd, err)
+			return
+		}
+
+		// Re-anchor the cursor to the same character offset in the replaced text, or the
+		// closest valid position on the same line if the replacement is shorter.
+		cursorPos := state.CursorPosition(s)
+		newEndPos := startPos + uint64(utf8.RuneCountInString(output))
+		newCursorPos := cursorPos
+		if newCursorPos > newEndPos {
+			newCursorPos = newEndPos
+		}
+
+		state.CheckpointUndo(s)
+		state.ReplaceRange(s, startPos, endPos, output)
+		state.MoveCursor(s, func(params state.LocatorParams) uint64 {
+			return locate.ClosestCharOnLine(params.TextTree, newCursorPos)
+		})
+	}
+}
+
+func filterScopeRegion(s *state.EditorState, scope FilterScope) (startPos, endPos uint64, err error) {
+	switch scope {
+	case ScopeBuffer:
+		return 0, state.TextLength(s), nil
+	case ScopeLine:
+		return state.CurrentLineBounds(s)
+	case ScopeSelection:
+		startPos, endPos, ok := state.SelectionRange(s)
+		if !ok {
+			return 0, 0, errors.New("no selection")
+		}
+		return startPos, endPos, nil
+	default:
+		return 0, 0, errors.Errorf("unknown filter scope %d", scope)
+	}
+}
+
+func runShellFilter(cmdStr string, input string) (string, error) {
+	cmd := osexec.Command("sh", "-c", cmdStr)
+	cmd.Stdin = strings.NewReader(input)
+
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return "", errors.Errorf("%s: %s", err, msg)
+		}
+		return "", errors.Wrapf(err, "running %q", cmdStr)
+	}
+
+	return stdout.String(), nil
+}
+
+func reportFilterError(s *state.EditorState, cmd string, err error) {
+	state.SetStatusMsg(s, state.StatusMsg{
+		Style: state.StatusMsgStyleError,
+		Text:  errors.Wrapf(err, "filter command %q", cmd).Error(),
+	})
+}
@@ -0,0 +1,22 @@
+package input
+
+import (
+	"github.com/gdamore/tcell/v2"
+
+	"github.com/aretext/aretext/state"
+)
+
+// MouseAction is like Action, but also receives the mouse event that triggered it. Dragging a
+// pane border needs the event's screen position, which a zero-arg Action has no way to see.
+type MouseAction func(*state.EditorState, *tcell.EventMouse)
+
+// DragLayoutBorder adjusts the weight of whichever layout split is under the mouse cursor to
+// track the drag, the same way a terminal multiplexer resizes panes as the user drags a border.
+// It is a no-op if the event isn't positioned over a border between two panes.
+func DragLayoutBorder(s *state.EditorState, event *tcell.EventMouse) {
+	if event.Buttons()&tcell.Button1 == 0 {
+		return
+	}
+	col, row := event.Position()
+	state.DragLayoutBorderTo(s, col, row)
+}
@@ -0,0 +1,39 @@
+package input
+
+import (
+	"github.com/aretext/aretext/exec"
+	"github.com/aretext/aretext/menu"
+	"github.com/aretext/aretext/plugin"
+)
+
+// PluginManagerMenuItems returns the built-in "install plugin", "update plugins", and "remove
+// plugin" commands for manager, appended to commandMenuItems alongside plugins' own commands.
+func PluginManagerMenuItems(manager *plugin.Manager) []menu.Item {
+	return []menu.Item{
+		{
+			Name: "install plugin",
+			Action: exec.NewPromptMutator("git url", func(url string) exec.Mutator {
+				return exec.NewRunFuncMutator(func() error {
+					return manager.InstallFromGit(url)
+				})
+			}),
+		},
+		{
+			Name: "update plugins",
+			Action: exec.NewRunFuncMutator(func() error {
+				if errs := manager.UpdatePlugins(); len(errs) > 0 {
+					return errs[0]
+				}
+				return nil
+			}),
+		},
+		{
+			Name: "remove plugin",
+			Action: exec.NewPromptMutator("plugin name", func(name string) exec.Mutator {
+				return exec.NewRunFuncMutator(func() error {
+					return manager.RemovePlugin(name)
+				})
+			}),
+		},
+	}
+}
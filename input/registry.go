@@ -0,0 +1,215 @@
+package input
+
+import (
+	"github.com/gdamore/tcell/v2"
+	"github.com/pkg/errors"
+
+	"github.com/aretext/aretext/config"
+)
+
+// ActionBuilder constructs an Action using the key sequence that triggered it, for commands
+// whose exact behavior depends on what the user typed (a target character, a repeat count)
+// rather than being fixed at bind time. Every entry in ActionBuilders has this shape, even
+// though the underlying functions in this package take varying extra arguments, so a
+// KeyBindingConfig can name either kind of action uniformly.
+type ActionBuilder func(inputEvents []*tcell.EventKey, countArg *uint64) Action
+
+// Actions is the registry of every fixed-arity Action in this package, keyed by the stable
+// name a KeyBindingConfig.Action refers to. Adding a new zero-argument Action to actions.go
+// should also add it here so it can be rebound from config.
+var Actions = map[string]Action{
+	"CursorLeft":                                 CursorLeft,
+	"CursorBack":                                  CursorBack,
+	"CursorRight":                                 CursorRight,
+	"CursorRightIncludeEndOfLineOrFile":           CursorRightIncludeEndOfLineOrFile,
+	"CursorUp":                                    CursorUp,
+	"CursorDown":                                  CursorDown,
+	"CursorNextWordStart":                         CursorNextWordStart,
+	"CursorPrevWordStart":                         CursorPrevWordStart,
+	"CursorNextWordEnd":                           CursorNextWordEnd,
+	"CursorPrevParagraph":                         CursorPrevParagraph,
+	"CursorNextParagraph":                         CursorNextParagraph,
+	"CursorLineStart":                             CursorLineStart,
+	"CursorLineStartNonWhitespace":                CursorLineStartNonWhitespace,
+	"CursorLineEnd":                               CursorLineEnd,
+	"CursorLineEndIncludeEndOfLineOrFile":         CursorLineEndIncludeEndOfLineOrFile,
+	"CursorStartOfLastLine":                       CursorStartOfLastLine,
+	"EnterInsertMode":                             EnterInsertMode,
+	"EnterInsertModeAtStartOfLine":                EnterInsertModeAtStartOfLine,
+	"EnterInsertModeAtNextPos":                    EnterInsertModeAtNextPos,
+	"EnterInsertModeAtEndOfLine":                  EnterInsertModeAtEndOfLine,
+	"ReturnToNormalMode":                          ReturnToNormalMode,
+	"ReturnToNormalModeAfterInsert":               ReturnToNormalModeAfterInsert,
+	"InsertNewlineAndUpdateAutoIndentWhitespace":  InsertNewlineAndUpdateAutoIndentWhitespace,
+	"InsertTab":                                   InsertTab,
+	"DeletePrevChar":                              DeletePrevChar,
+	"BeginNewLineBelow":                           BeginNewLineBelow,
+	"BeginNewLineAbove":                           BeginNewLineAbove,
+	"JoinLines":                                   JoinLines,
+	"DeletePrevCharInLine":                        DeletePrevCharInLine,
+	"DeleteDown":                                  DeleteDown,
+	"DeleteUp":                                    DeleteUp,
+	"DeleteToEndOfLine":                           DeleteToEndOfLine,
+	"DeleteToStartOfLine":                         DeleteToStartOfLine,
+	"DeleteToStartOfLineNonWhitespace":            DeleteToStartOfLineNonWhitespace,
+	"DeleteToStartOfNextWord":                     DeleteToStartOfNextWord,
+	"DeleteAWord":                                 DeleteAWord,
+	"DeleteInnerWord":                              DeleteInnerWord,
+	"ChangeToStartOfNextWord":                     ChangeToStartOfNextWord,
+	"ChangeAWord":                                 ChangeAWord,
+	"ChangeInnerWord":                             ChangeInnerWord,
+	"ToggleCaseAtCursor":                          ToggleCaseAtCursor,
+	"IndentLine":                                  IndentLine,
+	"OutdentLine":                                 OutdentLine,
+	"CopyToStartOfNextWord":                       CopyToStartOfNextWord,
+	"CopyAWord":                                   CopyAWord,
+	"CopyInnerWord":                               CopyInnerWord,
+	"CopyLines":                                   CopyLines,
+	"PasteAfterCursor":                            PasteAfterCursor,
+	"PasteBeforeCursor":                           PasteBeforeCursor,
+	"HideMenuAndReturnToNormalMode":               HideMenuAndReturnToNormalMode,
+	"ExecuteSelectedMenuItem":                     ExecuteSelectedMenuItem,
+	"MenuSelectionUp":                             MenuSelectionUp,
+	"MenuSelectionDown":                           MenuSelectionDown,
+	"DeleteRuneFromMenuSearch":                    DeleteRuneFromMenuSearch,
+	"StartSearchForward":                          StartSearchForward,
+	"StartSearchBackward":                         StartSearchBackward,
+	"AbortSearchAndReturnToNormalMode":            AbortSearchAndReturnToNormalMode,
+	"CommitSearchAndReturnToNormalMode":           CommitSearchAndReturnToNormalMode,
+	"DeleteRuneFromSearchQuery":                   DeleteRuneFromSearchQuery,
+	"FindNextMatch":                               FindNextMatch,
+	"FindPrevMatch":                               FindPrevMatch,
+	"Undo":                                         Undo,
+	"Redo":                                         Redo,
+	"ToggleVisualModeCharwise":                     ToggleVisualModeCharwise,
+	"ToggleVisualModeLinewise":                     ToggleVisualModeLinewise,
+	"DeleteSelectionAndReturnToNormalMode":         DeleteSelectionAndReturnToNormalMode,
+	"ToggleCaseInSelectionAndReturnToNormalMode":   ToggleCaseInSelectionAndReturnToNormalMode,
+	"IndentSelectionAndReturnToNormalMode":         IndentSelectionAndReturnToNormalMode,
+	"OutdentSelectionAndReturnToNormalMode":        OutdentSelectionAndReturnToNormalMode,
+	"ChangeSelection":                              ChangeSelection,
+	"CopySelectionAndReturnToNormalMode":           CopySelectionAndReturnToNormalMode,
+	"ClearSessionForFile":                          ClearSessionForFile,
+	"DuplicateLine":                                DuplicateLine,
+	"DeleteWordLeft":                               DeleteWordLeft,
+	"DeleteWordRight":                              DeleteWordRight,
+	"MoveLinesUp":                                  MoveLinesUp,
+	"MoveLinesDown":                                MoveLinesDown,
+	"TransposeChars":                               TransposeChars,
+}
+
+// ActionBuilders is the registry of Actions parameterized by the key sequence that triggered
+// them (a target character for f/F/t/T-style motions, a repeat count for dd-style commands).
+var ActionBuilders = map[string]ActionBuilder{
+	"CursorToNextMatchingChar": func(inputEvents []*tcell.EventKey, countArg *uint64) Action {
+		return CursorToNextMatchingChar(inputEvents, countArg, true)
+	},
+	"CursorToPrevMatchingChar": func(inputEvents []*tcell.EventKey, countArg *uint64) Action {
+		return CursorToPrevMatchingChar(inputEvents, countArg, true)
+	},
+	"DeleteLines": func(inputEvents []*tcell.EventKey, countArg *uint64) Action {
+		return DeleteLines(countArg)
+	},
+	"DeleteNextCharInLine": func(inputEvents []*tcell.EventKey, countArg *uint64) Action {
+		return DeleteNextCharInLine(countArg)
+	},
+	"CursorStartOfLineNum": func(inputEvents []*tcell.EventKey, countArg *uint64) Action {
+		return CursorStartOfLineNum(countArg)
+	},
+	"ReplaceCharacter": func(inputEvents []*tcell.EventKey, countArg *uint64) Action {
+		return ReplaceCharacter(inputEvents)
+	},
+}
+
+// KeyBindingID identifies a single (input mode, key sequence) pair that a KeyBindingConfig
+// can rebind, add, or disable.
+type KeyBindingID struct {
+	Mode string
+	Key  string
+}
+
+// ResolveKeyBindings looks up the Action named by each KeyBindingConfig in Actions, so
+// callers can merge user bindings over the parser's defaults. A KeyBindingConfig with
+// Actions set instead of Action resolves to those names run in order via Chain, under a
+// single undo checkpoint. KeyBindingConfigs that name an ActionBuilder instead of a fixed
+// Action are skipped here (they're absent from the returned map) because builders need the
+// inputEvents captured at the point the parser actually matches the key sequence; the parser
+// looks those up in ActionBuilders directly once kb.Key has been parsed into events.
+//
+// NOTE: wiring the returned bindings into the parser's per-mode key trees happens in the
+// parser construction code, which is not present in this snapshot of the repository.
+func ResolveKeyBindings(bindings []config.KeyBindingConfig) (map[KeyBindingID]Action, error) {
+	resolved := make(map[KeyBindingID]Action, len(bindings))
+	for _, kb := range bindings {
+		id := KeyBindingID{Mode: kb.Mode, Key: kb.Key}
+
+		if kb.Action == "RunFilterCommand" {
+			action, err := resolveRunFilterCommand(kb.Args)
+			if err != nil {
+				return nil, errors.Wrapf(err, "key binding for key %q", kb.Key)
+			}
+			resolved[id] = action
+			continue
+		}
+
+		if len(kb.Actions) > 0 {
+			chained, err := resolveChain(kb.Actions)
+			if err != nil {
+				return nil, errors.Wrapf(err, "key binding for key %q", kb.Key)
+			}
+			resolved[id] = chained
+			continue
+		}
+
+		if action, ok := Actions[kb.Action]; ok {
+			resolved[id] = action
+			continue
+		}
+		if _, ok := ActionBuilders[kb.Action]; ok {
+			continue
+		}
+		return nil, errors.Errorf("unknown action %q in key binding for key %q", kb.Action, kb.Key)
+	}
+	return resolved, nil
+}
+
+// resolveChain looks up each name in Actions and combines the results with Chain. Builder
+// names aren't accepted here: a chained binding has no single triggering key sequence to
+// hand a builder, since it's the whole Actions list, not one keypress, that the user names.
+func resolveChain(names []string) (Action, error) {
+	actions := make([]Action, 0, len(names))
+	for _, name := range names {
+		action, ok := Actions[name]
+		if !ok {
+			return nil, errors.Errorf("unknown action %q in chain", name)
+		}
+		actions = append(actions, action)
+	}
+	return Chain(actions...), nil
+}
+
+// resolveRunFilterCommand builds the Action for a KeyBindingConfig naming "RunFilterCommand",
+// reading the external command from args["cmd"] and the optional scope (one of "buffer"
+// (the default), "line", or "selection") from args["scope"].
+func resolveRunFilterCommand(args map[string]interface{}) (Action, error) {
+	cmd, _ := args["cmd"].(string)
+	if cmd == "" {
+		return nil, errors.New(`RunFilterCommand requires a non-empty "cmd" arg`)
+	}
+
+	scope := ScopeBuffer
+	if scopeName, ok := args["scope"].(string); ok && scopeName != "" {
+		switch scopeName {
+		case "buffer":
+			scope = ScopeBuffer
+		case "line":
+			scope = ScopeLine
+		case "selection":
+			scope = ScopeSelection
+		default:
+			return nil, errors.Errorf("unknown filter scope %q", scopeName)
+		}
+	}
+
+	return RunFilterCommand(cmd, scope), nil
+}
@@ -2,6 +2,7 @@ package text
 
 import (
 	"errors"
+	"fmt"
 	"io"
 )
 
@@ -32,8 +33,29 @@ func NewTree() *Tree {
 // This is more efficient than inserting the bytes into an empty tree.
 // Returns an error if the bytes are invalid UTF-8.
 func NewTreeFromReader(r io.Reader) (*Tree, error) {
+	return NewTreeFromReaderWithLimit(r, -1)
+}
+
+// MaxBytesError is returned by NewTreeFromReaderWithLimit when the reader produces
+// more than maxBytes of input. It mirrors the role of http.MaxBytesReader's error
+// for callers that need to distinguish "input too large" from other I/O failures.
+type MaxBytesError struct {
+	MaxBytes int64
+}
+
+func (e *MaxBytesError) Error() string {
+	return fmt.Sprintf("input exceeds limit of %d bytes", e.MaxBytes)
+}
+
+// NewTreeFromReaderWithLimit is like NewTreeFromReader, but returns a *MaxBytesError
+// if the reader produces more than maxBytes of input instead of reading until EOF.
+// A negative maxBytes means no limit, matching NewTreeFromReader's behavior.
+// Callers that open files of unknown provenance (for example, a file path supplied
+// on the command line) should prefer this over NewTreeFromReader to avoid
+// exhausting memory on a pathologically large input.
+func NewTreeFromReaderWithLimit(r io.Reader, maxBytes int64) (*Tree, error) {
 	validator := NewValidator()
-	leafGroups, err := bulkLoadIntoLeaves(r, validator)
+	leafGroups, err := bulkLoadIntoLeaves(r, validator, maxBytes)
 	if err != nil {
 		return nil, err
 	}
@@ -41,12 +63,13 @@ func NewTreeFromReader(r io.Reader) (*Tree, error) {
 	return &Tree{root, validator}, nil
 }
 
-func bulkLoadIntoLeaves(r io.Reader, v *Validator) ([]nodeGroup, error) {
+func bulkLoadIntoLeaves(r io.Reader, v *Validator, maxBytes int64) ([]nodeGroup, error) {
 	leafGroups := make([]nodeGroup, 0, 1)
 	currentGroup := &leafNodeGroup{numNodes: 1}
 	currentNode := &currentGroup.nodes[0]
 	leafGroups = append(leafGroups, currentGroup)
 
+	var totalBytes int64
 	var buf [1024]byte
 	for {
 		n, err := r.Read(buf[:])
@@ -58,6 +81,13 @@ func bulkLoadIntoLeaves(r io.Reader, v *Validator) ([]nodeGroup, error) {
 			break
 		}
 
+		if maxBytes >= 0 {
+			totalBytes += int64(n)
+			if totalBytes > maxBytes {
+				return nil, &MaxBytesError{MaxBytes: maxBytes}
+			}
+		}
+
 		if !v.ValidateBytes(buf[:n]) {
 			return nil, errors.New("invalid UTF-8")
 		}
@@ -121,10 +151,30 @@ func buildInnerNodesFromLeaves(leafGroups []nodeGroup) nodeGroup {
 	}
 }
 
+// maxTreeDepth bounds the number of levels CursorAtPosition will descend before giving up.
+// With maxNodesPerGroup-ary branching, this is far more than enough for any tree built by
+// this package; it exists to fail loudly on a corrupted tree instead of recursing (or, after
+// the iterative rewrite below, looping) without limit.
+const maxTreeDepth = 64
+
 // CursorAtPosition returns a cursor starting at the UTF-8 character at the specified position (0-indexed).
 // If the position is past the end of the text, the returned cursor will read zero bytes.
 func (t *Tree) CursorAtPosition(charPos uint64) *Cursor {
-	return t.root.cursorAtPosition(0, charPos)
+	group, nodeIdx := t.root, byte(0)
+	for depth := 0; ; depth++ {
+		if depth > maxTreeDepth {
+			panic("text.Tree: exceeded maximum tree depth, tree may be corrupted")
+		}
+
+		switch g := group.(type) {
+		case *innerNodeGroup:
+			node := &g.nodes[nodeIdx]
+			nodeIdx, charPos = node.childForPosition(charPos)
+			group = node.child
+		case *leafNodeGroup:
+			return g.cursorAtPosition(nodeIdx, charPos)
+		}
+	}
 }
 
 // text.Cursor reads UTF-8 bytes from a text.Tree.
@@ -174,7 +224,6 @@ const maxBytesPerLeaf = 63
 // nodeGroup is either an inner node group or a leaf node group.
 type nodeGroup interface {
 	keys() []indexKey
-	cursorAtPosition(nodeIdx byte, charPos uint64) *Cursor
 }
 
 // indexKey is used to navigate from an inner node to the child node containing a particular line or character offset.
@@ -205,10 +254,6 @@ func (g *innerNodeGroup) keys() []indexKey {
 	return keys
 }
 
-func (g *innerNodeGroup) cursorAtPosition(nodeIdx byte, charPos uint64) *Cursor {
-	return g.nodes[nodeIdx].cursorAtPosition(charPos)
-}
-
 // innerNode is used to navigate to the leaf node containing a character offset or line number.
 //
 // +-----------------------------------------+
@@ -234,18 +279,20 @@ func (n *innerNode) key() indexKey {
 	return nodeKey
 }
 
-func (n *innerNode) cursorAtPosition(charPos uint64) *Cursor {
+// childForPosition returns the index of the child key containing charPos and the
+// character offset of charPos relative to the start of that child.
+func (n *innerNode) childForPosition(charPos uint64) (byte, uint64) {
 	c := uint64(0)
 
 	for i := byte(0); i < n.numKeys-1; i++ {
 		nc := n.keys[i].numChars
 		if charPos < c+nc {
-			return n.child.cursorAtPosition(i, charPos-c)
+			return i, charPos - c
 		}
 		c += nc
 	}
 
-	return n.child.cursorAtPosition(n.numKeys-1, charPos-c)
+	return n.numKeys - 1, charPos - c
 }
 
 // leafNodeGroup is a group of leaf nodes referenced by an inner node.
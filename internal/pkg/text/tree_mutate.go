@@ -0,0 +1,395 @@
+package text
+
+import (
+	"errors"
+	"unicode/utf8"
+)
+
+// Insert inserts s into the tree at the given character position, maintaining the
+// indexKey{numChars,numLines} invariants cached on each innerNode.
+// It is optimized for interactive edits (inserting one or a few characters at a time, the
+// common case for a text editor) rather than bulk loading; use NewTreeFromReader to build a
+// tree from a whole file.
+// Returns an error if s is not valid UTF-8.
+func (t *Tree) Insert(charPos uint64, s string) error {
+	if !utf8.ValidString(s) {
+		return errors.New("invalid UTF-8")
+	}
+
+	for _, r := range s {
+		t.insertRune(charPos, r)
+		charPos++
+	}
+
+	return nil
+}
+
+func (t *Tree) insertRune(charPos uint64, r rune) {
+	var buf [utf8.UTFMax]byte
+	n := utf8.EncodeRune(buf[:], r)
+
+	root := t.root.(*innerNodeGroup)
+	node := &root.nodes[0]
+	childIdx, childCharPos := node.childForPosition(charPos)
+	sibling := insertIntoGroup(node.child, childIdx, childCharPos, buf[:n])
+	// Refresh the whole key cache rather than just keys[childIdx]: node.child may have
+	// gained a new node (shifting every key after childIdx), not just changed the content
+	// of an existing one.
+	setKeys(node, node.child)
+	if sibling == nil {
+		return
+	}
+
+	// The subtree below the root's single node just gained a new top-level sibling. Wrap
+	// both of them in a fresh root so the root group keeps exactly one node, which is what
+	// CursorAtPosition (and the rest of this file) assume when starting their descent at
+	// nodeIdx 0.
+	newNode := innerNode{child: sibling}
+	setKeys(&newNode, sibling)
+
+	middleGroup := &innerNodeGroup{numNodes: 2}
+	middleGroup.nodes[0] = *node
+	middleGroup.nodes[1] = newNode
+
+	newRoot := &innerNodeGroup{numNodes: 1}
+	newRoot.nodes[0] = innerNode{child: middleGroup}
+	setKeys(&newRoot.nodes[0], middleGroup)
+	t.root = newRoot
+}
+
+// insertIntoGroup inserts data (already-valid UTF-8 bytes, no longer than utf8.UTFMax) at the
+// character offset charPos within the subtree rooted at group.nodes[nodeIdx], updating the
+// cached indexKeys of any innerNode it passes through on the way down.
+// If the insert overflows group, it splits group and returns the new sibling group; the
+// caller is responsible for inserting an entry referencing the sibling into its own group
+// (which may in turn overflow and need to bubble up further).
+func insertIntoGroup(group nodeGroup, nodeIdx byte, charPos uint64, data []byte) nodeGroup {
+	switch g := group.(type) {
+	case *leafNodeGroup:
+		return insertIntoLeaf(g, nodeIdx, charPos, data)
+	case *innerNodeGroup:
+		node := &g.nodes[nodeIdx]
+		childIdx, childCharPos := node.childForPosition(charPos)
+		sibling := insertIntoGroup(node.child, childIdx, childCharPos, data)
+		// As in insertRune, refresh the whole key cache: node.child may have gained a new
+		// node (shifting every key after childIdx), not just changed an existing one.
+		setKeys(node, node.child)
+		if sibling == nil {
+			return nil
+		}
+
+		newNode := innerNode{child: sibling}
+		setKeys(&newNode, sibling)
+		return insertInnerIntoGroup(g, nodeIdx+1, newNode)
+	default:
+		panic("text.Tree: unknown nodeGroup implementation")
+	}
+}
+
+// insertIntoLeaf inserts data into the leaf at g.nodes[nodeIdx] at the given character
+// offset. If the leaf doesn't have room, it splits at the insertion point and places data on
+// whichever side has space -- at least one always does, since a leaf holds at most
+// maxBytesPerLeaf bytes and data is at most utf8.UTFMax bytes.
+func insertIntoLeaf(g *leafNodeGroup, nodeIdx byte, charPos uint64, data []byte) nodeGroup {
+	leaf := &g.nodes[nodeIdx]
+	byteOffset := leaf.byteOffsetForPosition(charPos)
+
+	if int(leaf.numBytes)+len(data) <= maxBytesPerLeaf {
+		copy(leaf.textBytes[int(byteOffset)+len(data):], leaf.textBytes[byteOffset:leaf.numBytes])
+		copy(leaf.textBytes[byteOffset:], data)
+		leaf.numBytes += byte(len(data))
+		return nil
+	}
+
+	var newLeaf leafNode
+	tailLen := leaf.numBytes - byteOffset
+	copy(newLeaf.textBytes[:tailLen], leaf.textBytes[byteOffset:leaf.numBytes])
+	newLeaf.numBytes = tailLen
+	leaf.numBytes = byteOffset
+
+	if int(leaf.numBytes)+len(data) <= maxBytesPerLeaf {
+		copy(leaf.textBytes[leaf.numBytes:], data)
+		leaf.numBytes += byte(len(data))
+	} else {
+		copy(newLeaf.textBytes[len(data):int(newLeaf.numBytes)+len(data)], newLeaf.textBytes[:newLeaf.numBytes])
+		copy(newLeaf.textBytes[:], data)
+		newLeaf.numBytes += byte(len(data))
+	}
+
+	return insertLeafIntoGroup(g, nodeIdx+1, newLeaf)
+}
+
+// insertLeafIntoGroup inserts node at position `at` in g, splitting g if it's already full.
+// It returns the new sibling group if g split, or nil otherwise.
+func insertLeafIntoGroup(g *leafNodeGroup, at byte, node leafNode) nodeGroup {
+	if uint64(g.numNodes) < uint64(maxNodesPerGroup) {
+		insertLeafNodeAt(g, at, node)
+		return nil
+	}
+
+	mid := byte(g.numNodes / 2)
+	sibling := splitLeafGroup(g, mid)
+	if at > mid {
+		insertLeafNodeAt(sibling, at-mid, node)
+	} else {
+		insertLeafNodeAt(g, at, node)
+	}
+	return sibling
+}
+
+func insertLeafNodeAt(g *leafNodeGroup, at byte, node leafNode) {
+	copy(g.nodes[at+1:uint64(g.numNodes)+1], g.nodes[at:g.numNodes])
+	g.nodes[at] = node
+	g.numNodes++
+}
+
+// splitLeafGroup moves the nodes at and after `at` out of g into a new group spliced into
+// the leafNodeGroup linked list immediately after g.
+func splitLeafGroup(g *leafNodeGroup, at byte) *leafNodeGroup {
+	sibling := &leafNodeGroup{}
+	n := uint64(g.numNodes) - uint64(at)
+	copy(sibling.nodes[:n], g.nodes[at:g.numNodes])
+	sibling.numNodes = n
+	g.numNodes = uint64(at)
+
+	sibling.prev = g
+	sibling.next = g.next
+	if g.next != nil {
+		g.next.prev = sibling
+	}
+	g.next = sibling
+	return sibling
+}
+
+// insertInnerIntoGroup inserts node at position `at` in g, splitting g if it's already full.
+// It returns the new sibling group if g split, or nil otherwise.
+func insertInnerIntoGroup(g *innerNodeGroup, at byte, node innerNode) nodeGroup {
+	if uint64(g.numNodes) < uint64(maxNodesPerGroup) {
+		insertInnerNodeAt(g, at, node)
+		return nil
+	}
+
+	mid := byte(g.numNodes / 2)
+	sibling := &innerNodeGroup{}
+	n := uint64(g.numNodes) - uint64(mid)
+	copy(sibling.nodes[:n], g.nodes[mid:g.numNodes])
+	sibling.numNodes = n
+	g.numNodes = uint64(mid)
+
+	if at > mid {
+		insertInnerNodeAt(sibling, at-mid, node)
+	} else {
+		insertInnerNodeAt(g, at, node)
+	}
+	return sibling
+}
+
+func insertInnerNodeAt(g *innerNodeGroup, at byte, node innerNode) {
+	copy(g.nodes[at+1:uint64(g.numNodes)+1], g.nodes[at:g.numNodes])
+	g.nodes[at] = node
+	g.numNodes++
+}
+
+// keyAt returns the cached key of child.nodes[idx], whether child is a leaf or inner group.
+func keyAt(child nodeGroup, idx byte) indexKey {
+	switch c := child.(type) {
+	case *leafNodeGroup:
+		return c.nodes[idx].key()
+	case *innerNodeGroup:
+		return c.nodes[idx].key()
+	default:
+		panic("text.Tree: unknown nodeGroup implementation")
+	}
+}
+
+// setKeys populates n.keys from child's own nodes, as if n.child == child.
+func setKeys(n *innerNode, child nodeGroup) {
+	n.numKeys = 0
+	for i, k := range child.keys() {
+		n.keys[i] = k
+		n.numKeys++
+	}
+}
+
+// Delete removes count UTF-8 characters starting at charPos, maintaining the indexKey
+// invariants in the same way Insert does. Like Insert, it is optimized for small interactive
+// edits rather than bulk changes.
+//
+// For simplicity, leaves emptied out by deletion are left in place as zero-length nodes
+// rather than merged with a neighbor; this trades a little memory density for not having to
+// thread node removal (and the corresponding shrinking of innerNode.keys) back up through the
+// same paths Insert uses to grow them.
+func (t *Tree) Delete(charPos, count uint64) {
+	for i := uint64(0); i < count; i++ {
+		t.deleteRune(charPos)
+	}
+}
+
+func (t *Tree) deleteRune(charPos uint64) {
+	root := t.root.(*innerNodeGroup)
+	node := &root.nodes[0]
+	childIdx, childCharPos := node.childForPosition(charPos)
+	deleteFromGroup(node.child, childIdx, childCharPos)
+	node.keys[childIdx] = keyAt(node.child, childIdx)
+}
+
+func deleteFromGroup(group nodeGroup, nodeIdx byte, charPos uint64) {
+	switch g := group.(type) {
+	case *leafNodeGroup:
+		deleteFromLeafGroup(g, nodeIdx, charPos)
+	case *innerNodeGroup:
+		node := &g.nodes[nodeIdx]
+		childIdx, childCharPos := node.childForPosition(charPos)
+		deleteFromGroup(node.child, childIdx, childCharPos)
+		node.keys[childIdx] = keyAt(node.child, childIdx)
+	default:
+		panic("text.Tree: unknown nodeGroup implementation")
+	}
+}
+
+// deleteFromLeafGroup removes the character at charPos from g.nodes[nodeIdx]. If that
+// character's bytes extend past the end of this leaf -- which happens when
+// bulkLoadIntoLeaves split a multi-byte character across a leaf boundary -- it continues
+// removing the remaining bytes from the start of the following leaf(s).
+func deleteFromLeafGroup(g *leafNodeGroup, nodeIdx byte, charPos uint64) {
+	leaf := &g.nodes[nodeIdx]
+	start := leaf.byteOffsetForPosition(charPos)
+	if start == leaf.numBytes {
+		return
+	}
+
+	removeFromLeafChain(g, nodeIdx, start, runeByteWidth(leaf.textBytes[start]))
+}
+
+// removeFromLeafChain removes n bytes starting at byte offset `at` in g.nodes[nodeIdx],
+// continuing into subsequent leaves (within g or, via the leafNodeGroup linked list, a later
+// group) if the run extends past the end of the current leaf.
+func removeFromLeafChain(g *leafNodeGroup, nodeIdx byte, at, n byte) {
+	for n > 0 {
+		leaf := &g.nodes[nodeIdx]
+		removed := leaf.numBytes - at
+		if removed > n {
+			removed = n
+		}
+		copy(leaf.textBytes[at:], leaf.textBytes[at+removed:leaf.numBytes])
+		leaf.numBytes -= removed
+		n -= removed
+
+		if n == 0 {
+			return
+		}
+
+		nodeIdx++
+		at = 0
+		if uint64(nodeIdx) == g.numNodes {
+			if g.next == nil {
+				return
+			}
+			g = g.next
+			nodeIdx = 0
+		}
+	}
+}
+
+// runeByteWidth returns the number of bytes in the UTF-8 character starting with lead.
+// A lead byte that is itself a continuation byte (top two bits 0b10) means the character
+// started in a previous leaf; in that case only this single, already-accounted-for byte
+// belongs to it.
+func runeByteWidth(lead byte) byte {
+	switch {
+	case lead>>7 == 0:
+		return 1
+	case lead>>5 == 0b110:
+		return 2
+	case lead>>4 == 0b1110:
+		return 3
+	case lead>>3 == 0b11110:
+		return 4
+	default:
+		return 1
+	}
+}
+
+// childForLine returns the index of the child key containing lineNum, the line number
+// offset of lineNum relative to the start of that child, and the number of characters in
+// the children skipped over to reach it.
+func (n *innerNode) childForLine(lineNum uint64) (childIdx byte, localLineNum uint64, charsBefore uint64) {
+	var lines uint64
+	for i := byte(0); i < n.numKeys-1; i++ {
+		nl := n.keys[i].numLines
+		if lineNum <= lines+nl {
+			return i, lineNum - lines, charsBefore
+		}
+		lines += nl
+		charsBefore += n.keys[i].numChars
+	}
+	return n.numKeys - 1, lineNum - lines, charsBefore
+}
+
+// charOffsetAfterLine scans for the n-th (1-indexed) line break in the leaf. It returns the
+// number of characters up to and including that line break and whether it was found in this
+// leaf. If not found, charsInLeaf is the leaf's full character count, so the caller can keep
+// accumulating before falling back to clamping at the end of the text.
+func (l *leafNode) charOffsetAfterLine(n uint64) (charsInLeaf uint64, found bool) {
+	var lines uint64
+	for _, b := range l.textBytes[:l.numBytes] {
+		charsInLeaf += uint64(utf8StartByteIndicator[b])
+		if b == '\n' {
+			lines++
+			if lines == n {
+				return charsInLeaf, true
+			}
+		}
+	}
+	return charsInLeaf, false
+}
+
+// totalKey returns the aggregate indexKey (character and line counts) for the whole tree.
+func (t *Tree) totalKey() indexKey {
+	return t.root.(*innerNodeGroup).nodes[0].key()
+}
+
+// LineCount returns the number of lines in the tree. An empty tree has one line.
+func (t *Tree) LineCount() uint64 {
+	return t.totalKey().numLines + 1
+}
+
+// LineStartPosition returns the character position of the start of the given line
+// (0-indexed). A lineNum past the end of the text returns the length of the text.
+func (t *Tree) LineStartPosition(lineNum uint64) uint64 {
+	if lineNum == 0 {
+		return 0
+	}
+
+	group, nodeIdx, remaining, charOffset := t.root, byte(0), lineNum, uint64(0)
+	for depth := 0; ; depth++ {
+		if depth > maxTreeDepth {
+			panic("text.Tree: exceeded maximum tree depth, tree may be corrupted")
+		}
+
+		switch g := group.(type) {
+		case *innerNodeGroup:
+			node := &g.nodes[nodeIdx]
+			childIdx, childRemaining, charsBefore := node.childForLine(remaining)
+			charOffset += charsBefore
+			nodeIdx, remaining = childIdx, childRemaining
+			group = node.child
+		case *leafNodeGroup:
+			charsInLeaf, ok := g.nodes[nodeIdx].charOffsetAfterLine(remaining)
+			if !ok {
+				return t.totalKey().numChars
+			}
+			return charOffset + charsInLeaf
+		}
+	}
+}
+
+// PositionAfter returns the character position immediately following charPos, clamped to the
+// length of the text.
+func (t *Tree) PositionAfter(charPos uint64) uint64 {
+	total := t.totalKey().numChars
+	if charPos >= total {
+		return total
+	}
+	return charPos + 1
+}
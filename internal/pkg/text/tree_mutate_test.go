@@ -0,0 +1,142 @@
+package text
+
+import (
+	"bytes"
+	"io"
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func treeString(t *testing.T, tree *Tree) string {
+	var buf bytes.Buffer
+	_, err := io.Copy(&buf, tree.CursorAtPosition(0))
+	assert.NoError(t, err)
+	return buf.String()
+}
+
+func TestTreeInsertDelete(t *testing.T) {
+	testCases := []struct {
+		name    string
+		initial string
+		edits   func(tree *Tree) error
+		expect  string
+	}{
+		{
+			name:    "insert into empty tree",
+			initial: "",
+			edits: func(tree *Tree) error {
+				return tree.Insert(0, "abc")
+			},
+			expect: "abc",
+		},
+		{
+			name:    "insert in the middle",
+			initial: "ac",
+			edits: func(tree *Tree) error {
+				return tree.Insert(1, "b")
+			},
+			expect: "abc",
+		},
+		{
+			name:    "insert multi-byte characters",
+			initial: "",
+			edits: func(tree *Tree) error {
+				return tree.Insert(0, "héllo wörld")
+			},
+			expect: "héllo wörld",
+		},
+		{
+			name:    "insert past a leaf's capacity",
+			initial: "",
+			edits: func(tree *Tree) error {
+				return tree.Insert(0, strings.Repeat("x", maxBytesPerLeaf*3))
+			},
+			expect: strings.Repeat("x", maxBytesPerLeaf*3),
+		},
+		{
+			name:    "delete from the middle",
+			initial: "abc",
+			edits: func(tree *Tree) error {
+				tree.Delete(1, 1)
+				return nil
+			},
+			expect: "ac",
+		},
+		{
+			name:    "delete multi-byte character",
+			initial: "héllo",
+			edits: func(tree *Tree) error {
+				tree.Delete(1, 1)
+				return nil
+			},
+			expect: "hllo",
+		},
+		{
+			name:    "insert then delete everything",
+			initial: "",
+			edits: func(tree *Tree) error {
+				if err := tree.Insert(0, "hello world"); err != nil {
+					return err
+				}
+				tree.Delete(0, 11)
+				return nil
+			},
+			expect: "",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			tree, err := NewTreeFromReader(strings.NewReader(tc.initial))
+			assert.NoError(t, err)
+			assert.NoError(t, tc.edits(tree))
+			assert.Equal(t, tc.expect, treeString(t, tree))
+		})
+	}
+}
+
+func TestTreeLineStartPosition(t *testing.T) {
+	tree, err := NewTreeFromReader(strings.NewReader("ab\ncd\n\nef"))
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(4), tree.LineCount())
+	assert.Equal(t, uint64(0), tree.LineStartPosition(0))
+	assert.Equal(t, uint64(3), tree.LineStartPosition(1))
+	assert.Equal(t, uint64(6), tree.LineStartPosition(2))
+	assert.Equal(t, uint64(7), tree.LineStartPosition(3))
+	assert.Equal(t, uint64(9), tree.LineStartPosition(4)) // past the end, clamps to text length
+}
+
+// FuzzTreeInsertDelete compares Tree against a naive []rune reference implementation under
+// random sequences of inserts and deletes, to catch invariant violations (leaf and group
+// split bookkeeping, UTF-8 boundary handling) that are easy to get wrong in the B+tree bookkeeping
+// but simple to check against a dumb reference.
+func FuzzTreeInsertDelete(f *testing.F) {
+	f.Add("hello world", uint64(3), "XY", uint64(1), uint64(2))
+	f.Add("", uint64(0), "héllo", uint64(0), uint64(0))
+
+	f.Fuzz(func(t *testing.T, initial string, insertPos uint64, insertStr string, deletePos uint64, deleteCount uint64) {
+		if !utf8.ValidString(initial) || !utf8.ValidString(insertStr) {
+			t.Skip("only valid UTF-8 is a supported input")
+		}
+
+		reference := []rune(initial)
+		tree, err := NewTreeFromReader(strings.NewReader(initial))
+		assert.NoError(t, err)
+
+		pos := insertPos % uint64(len(reference)+1)
+		reference = append(reference[:pos], append([]rune(insertStr), reference[pos:]...)...)
+		assert.NoError(t, tree.Insert(pos, insertStr))
+
+		if len(reference) > 0 {
+			delPos := deletePos % uint64(len(reference))
+			delCount := deleteCount % (uint64(len(reference)) - delPos + 1)
+			reference = append(reference[:delPos], reference[delPos+delCount:]...)
+			tree.Delete(delPos, delCount)
+		}
+
+		assert.Equal(t, string(reference), treeString(t, tree))
+	})
+}
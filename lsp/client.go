@@ -0,0 +1,288 @@
+// Package lsp implements a minimal Language Server Protocol client: JSON-RPC 2.0 framing over
+// a language server subprocess's stdin/stdout, with FIFO-ordered outgoing messages and
+// correlated request/response calls. It does not itself know about aretext's buffer or
+// display model; callers translate edits into didChange notifications and diagnostics into
+// whatever the editor draws them as.
+package lsp
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os/exec"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// shutdownTimeout bounds how long Shutdown waits for the server to answer the "shutdown"
+// request before giving up and killing the subprocess, so a hung or already-dead server can't
+// block the editor from exiting.
+const shutdownTimeout = 5 * time.Second
+
+// ServerConfig configures how to launch and address a language server for a filetype.
+type ServerConfig struct {
+	// Command is the server executable (e.g. "gopls").
+	Command string
+	// Args are passed to Command.
+	Args []string
+	// RootMarkers are searched for via RootDirForFile to locate the server's workspace root.
+	RootMarkers []string
+}
+
+type jsonrpcMessage struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      *int64          `json:"id,omitempty"`
+	Method  string          `json:"method,omitempty"`
+	Params  json.RawMessage `json:"params,omitempty"`
+	Result  json.RawMessage `json:"result,omitempty"`
+	Error   *jsonrpcError   `json:"error,omitempty"`
+}
+
+type jsonrpcError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+// Client is a running connection to one language server subprocess.
+type Client struct {
+	cmd     *exec.Cmd
+	stdin   io.WriteCloser
+	writeMu sync.Mutex // serializes writes so notifications/requests reach the server in FIFO order
+
+	mu      sync.Mutex
+	nextID  int64
+	pending map[int64]chan jsonrpcMessage
+
+	notifications chan *jsonrpcMessage
+
+	closeOnce sync.Once
+}
+
+// Start launches cfg.Command as a subprocess and begins reading its output in the
+// background. It does not itself send "initialize"; callers issue that as a Call so they can
+// pass capabilities and rootDir in whatever shape the caller's LSP version needs.
+func Start(cfg ServerConfig) (*Client, error) {
+	cmd := exec.Command(cfg.Command, cfg.Args...)
+
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		return nil, errors.Wrapf(err, "cmd.StdinPipe")
+	}
+
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, errors.Wrapf(err, "cmd.StdoutPipe")
+	}
+
+	if err := cmd.Start(); err != nil {
+		return nil, errors.Wrapf(err, "cmd.Start")
+	}
+
+	c := &Client{
+		cmd:           cmd,
+		stdin:         stdin,
+		pending:       make(map[int64]chan jsonrpcMessage),
+		notifications: make(chan *jsonrpcMessage, 64),
+	}
+	go c.readLoop(bufio.NewReader(stdout))
+	return c, nil
+}
+
+// Notify sends a JSON-RPC notification (no response expected), such as textDocument/didOpen
+// or textDocument/didChange. Notify calls on a single Client reach the subprocess in the
+// order they were made, so a sequence of incremental didChange edits always arrives in the
+// order the user made them.
+func (c *Client) Notify(method string, params interface{}) error {
+	data, err := json.Marshal(params)
+	if err != nil {
+		return errors.Wrapf(err, "json.Marshal")
+	}
+	return c.write(jsonrpcMessage{JSONRPC: "2.0", Method: method, Params: data})
+}
+
+// Call sends a JSON-RPC request and blocks until the server responds or ctx is done,
+// returning the response's raw result for the caller to unmarshal into the relevant LSP type
+// (CompletionList, Hover, []TextEdit, and so on).
+func (c *Client) Call(ctx context.Context, method string, params interface{}) (json.RawMessage, error) {
+	data, err := json.Marshal(params)
+	if err != nil {
+		return nil, errors.Wrapf(err, "json.Marshal")
+	}
+
+	c.mu.Lock()
+	id := c.nextID
+	c.nextID++
+	respCh := make(chan jsonrpcMessage, 1)
+	c.pending[id] = respCh
+	c.mu.Unlock()
+
+	if err := c.write(jsonrpcMessage{JSONRPC: "2.0", ID: &id, Method: method, Params: data}); err != nil {
+		c.mu.Lock()
+		delete(c.pending, id)
+		c.mu.Unlock()
+		return nil, err
+	}
+
+	select {
+	case resp := <-respCh:
+		if resp.Error != nil {
+			return nil, errors.Errorf("%s: %s (code %d)", method, resp.Error.Message, resp.Error.Code)
+		}
+		return resp.Result, nil
+	case <-ctx.Done():
+		c.mu.Lock()
+		delete(c.pending, id)
+		c.mu.Unlock()
+		return nil, errors.Wrapf(ctx.Err(), "%s", method)
+	}
+}
+
+// Notifications returns the channel of messages the server sends unprompted - diagnostics,
+// log messages, and so on. Callers should drain it continuously; once the server's stdout
+// closes, the channel is closed too.
+func (c *Client) Notifications() <-chan *jsonrpcMessage {
+	return c.notifications
+}
+
+// Shutdown runs the standard LSP shutdown/exit sequence and waits for the subprocess to
+// exit, so quitting the editor doesn't leave orphaned language servers running. It returns the
+// first error encountered; later steps still run (best-effort) so a failed shutdown request
+// doesn't leave the subprocess running or stdin open.
+func (c *Client) Shutdown() error {
+	var shutdownErr error
+	c.closeOnce.Do(func() {
+		ctx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+		defer cancel()
+
+		if _, err := c.Call(ctx, "shutdown", nil); err != nil {
+			shutdownErr = err
+		}
+		if err := c.Notify("exit", nil); err != nil && shutdownErr == nil {
+			shutdownErr = err
+		}
+		c.stdin.Close()
+
+		waitErr := make(chan error, 1)
+		go func() { waitErr <- c.cmd.Wait() }()
+
+		select {
+		case err := <-waitErr:
+			if shutdownErr == nil {
+				shutdownErr = err
+			}
+		case <-time.After(shutdownTimeout):
+			// The server ignored "exit" (or is otherwise stuck); killing it is the only way
+			// to keep Wait from blocking the editor's own exit indefinitely.
+			c.cmd.Process.Kill()
+			<-waitErr
+			if shutdownErr == nil {
+				shutdownErr = errors.Errorf("lsp: server did not exit within %s of \"exit\", killed", shutdownTimeout)
+			}
+		}
+	})
+	return shutdownErr
+}
+
+func (c *Client) write(msg jsonrpcMessage) error {
+	data, err := json.Marshal(msg)
+	if err != nil {
+		return errors.Wrapf(err, "json.Marshal")
+	}
+
+	c.writeMu.Lock()
+	defer c.writeMu.Unlock()
+
+	header := fmt.Sprintf("Content-Length: %d\r\n\r\n", len(data))
+	if _, err := io.WriteString(c.stdin, header); err != nil {
+		return errors.Wrapf(err, "io.WriteString")
+	}
+	if _, err := c.stdin.Write(data); err != nil {
+		return errors.Wrapf(err, "stdin.Write")
+	}
+	return nil
+}
+
+func (c *Client) readLoop(r *bufio.Reader) {
+	defer close(c.notifications)
+
+	for {
+		msg, err := readMessage(r)
+		if err != nil {
+			// The server's stdout closed (or sent something unparseable). Fail every Call
+			// still waiting on a response instead of leaving it blocked forever.
+			c.failAllPending(errors.Wrapf(err, "lsp: connection closed"))
+			return
+		}
+
+		if msg.ID != nil && msg.Method == "" {
+			// A response to one of our own Call()s.
+			c.mu.Lock()
+			ch, ok := c.pending[*msg.ID]
+			delete(c.pending, *msg.ID)
+			c.mu.Unlock()
+			if ok {
+				ch <- *msg
+			}
+			continue
+		}
+
+		// Blocking send: a caller that wants to avoid stalling the read loop should drain
+		// Notifications() on its own goroutine. Dropping a missed diagnostics push silently
+		// would let the editor show stale diagnostics with no indication they're out of date.
+		c.notifications <- msg
+	}
+}
+
+// failAllPending delivers err, wrapped as a jsonrpcError, to every Call still waiting on a
+// response, so a server exit or broken pipe doesn't leave callers blocked forever.
+func (c *Client) failAllPending(err error) {
+	c.mu.Lock()
+	pending := c.pending
+	c.pending = make(map[int64]chan jsonrpcMessage)
+	c.mu.Unlock()
+
+	for _, ch := range pending {
+		ch <- jsonrpcMessage{Error: &jsonrpcError{Message: err.Error()}}
+	}
+}
+
+// readMessage reads one Content-Length-framed JSON-RPC message from r.
+func readMessage(r *bufio.Reader) (*jsonrpcMessage, error) {
+	var contentLength int
+	for {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			return nil, err
+		}
+
+		line = strings.TrimRight(line, "\r\n")
+		if line == "" {
+			break
+		}
+
+		if idx := strings.Index(line, ":"); idx >= 0 && strings.EqualFold(strings.TrimSpace(line[:idx]), "Content-Length") {
+			n, err := strconv.Atoi(strings.TrimSpace(line[idx+1:]))
+			if err != nil {
+				return nil, errors.Wrapf(err, "strconv.Atoi")
+			}
+			contentLength = n
+		}
+	}
+
+	body := make([]byte, contentLength)
+	if _, err := io.ReadFull(r, body); err != nil {
+		return nil, err
+	}
+
+	var msg jsonrpcMessage
+	if err := json.Unmarshal(body, &msg); err != nil {
+		return nil, errors.Wrapf(err, "json.Unmarshal")
+	}
+	return &msg, nil
+}
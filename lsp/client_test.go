@@ -0,0 +1,48 @@
+package lsp
+
+import (
+	"bufio"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReadMessage(t *testing.T) {
+	body := `{"jsonrpc":"2.0","method":"textDocument/publishDiagnostics","params":{"uri":"file:///a.go"}}`
+	framed := fmt.Sprintf("Content-Length: %d\r\n\r\n%s", len(body), body)
+
+	msg, err := readMessage(bufio.NewReader(strings.NewReader(framed)))
+	require.NoError(t, err)
+	assert.Equal(t, "textDocument/publishDiagnostics", msg.Method)
+	assert.Nil(t, msg.ID)
+}
+
+func TestReadMessageResponse(t *testing.T) {
+	body := `{"jsonrpc":"2.0","id":3,"result":{"ok":true}}`
+	framed := fmt.Sprintf("Content-Length: %d\r\n\r\n%s", len(body), body)
+
+	msg, err := readMessage(bufio.NewReader(strings.NewReader(framed)))
+	require.NoError(t, err)
+	require.NotNil(t, msg.ID)
+	assert.Equal(t, int64(3), *msg.ID)
+	assert.JSONEq(t, `{"ok":true}`, string(msg.Result))
+}
+
+func TestReadMessageTwoInARow(t *testing.T) {
+	body1 := `{"jsonrpc":"2.0","method":"a"}`
+	body2 := `{"jsonrpc":"2.0","method":"b"}`
+	framed := fmt.Sprintf("Content-Length: %d\r\n\r\n%sContent-Length: %d\r\n\r\n%s", len(body1), body1, len(body2), body2)
+
+	r := bufio.NewReader(strings.NewReader(framed))
+
+	first, err := readMessage(r)
+	require.NoError(t, err)
+	assert.Equal(t, "a", first.Method)
+
+	second, err := readMessage(r)
+	require.NoError(t, err)
+	assert.Equal(t, "b", second.Method)
+}
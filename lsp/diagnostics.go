@@ -0,0 +1,50 @@
+package lsp
+
+import (
+	"encoding/json"
+	"log"
+	"sync"
+)
+
+// DiagnosticsStore tracks the most recent textDocument/publishDiagnostics notification for
+// each URI a server has reported on, so the display layer can render them without every caller
+// having to parse Client.Notifications() itself.
+type DiagnosticsStore struct {
+	mu    sync.RWMutex
+	byURI map[string][]Diagnostic
+}
+
+// NewDiagnosticsStore starts draining client's Notifications() in the background, recording
+// every textDocument/publishDiagnostics push it sees. It stops when the Client's notifications
+// channel closes (the server exited or the connection broke).
+func NewDiagnosticsStore(client *Client) *DiagnosticsStore {
+	store := &DiagnosticsStore{byURI: make(map[string][]Diagnostic)}
+	go store.run(client)
+	return store
+}
+
+func (s *DiagnosticsStore) run(client *Client) {
+	for msg := range client.Notifications() {
+		if msg.Method != "textDocument/publishDiagnostics" {
+			continue
+		}
+
+		var params PublishDiagnosticsParams
+		if err := json.Unmarshal(msg.Params, &params); err != nil {
+			log.Printf("lsp: malformed publishDiagnostics params: %v", err)
+			continue
+		}
+
+		s.mu.Lock()
+		s.byURI[params.URI] = params.Diagnostics
+		s.mu.Unlock()
+	}
+}
+
+// DiagnosticsForURI returns the most recently published diagnostics for uri, or nil if the
+// server hasn't reported any.
+func (s *DiagnosticsStore) DiagnosticsForURI(uri string) []Diagnostic {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.byURI[uri]
+}
@@ -0,0 +1,152 @@
+package lsp
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/pkg/errors"
+)
+
+// textDocumentItem is the params shape textDocument/didOpen expects for the document it's
+// opening.
+type textDocumentItem struct {
+	URI        string `json:"uri"`
+	LanguageID string `json:"languageId"`
+	Version    int    `json:"version"`
+	Text       string `json:"text"`
+}
+
+// DidOpen notifies the server that uri is now open in the editor, so it starts tracking edits
+// and publishing diagnostics for it.
+func (c *Client) DidOpen(uri, languageID string, version int, text string) error {
+	return c.Notify("textDocument/didOpen", struct {
+		TextDocument textDocumentItem `json:"textDocument"`
+	}{
+		TextDocument: textDocumentItem{URI: uri, LanguageID: languageID, Version: version, Text: text},
+	})
+}
+
+// DidChange notifies the server of edits to uri since its last known version. Passing
+// incremental changes (each with a non-nil Range) lets the server avoid reparsing the whole
+// document; a single change with a nil Range replaces the full text, which is required the
+// first time a document changes after DidOpen if the caller hasn't tracked fine-grained edits.
+func (c *Client) DidChange(uri string, version int, changes []TextDocumentContentChangeEvent) error {
+	return c.Notify("textDocument/didChange", struct {
+		TextDocument   VersionedTextDocumentIdentifier  `json:"textDocument"`
+		ContentChanges []TextDocumentContentChangeEvent `json:"contentChanges"`
+	}{
+		TextDocument:   VersionedTextDocumentIdentifier{URI: uri, Version: version},
+		ContentChanges: changes,
+	})
+}
+
+// DidSave notifies the server that uri was written to disk.
+func (c *Client) DidSave(uri string) error {
+	return c.Notify("textDocument/didSave", struct {
+		TextDocument TextDocumentIdentifier `json:"textDocument"`
+	}{
+		TextDocument: TextDocumentIdentifier{URI: uri},
+	})
+}
+
+// DidClose notifies the server that uri is no longer open in the editor.
+func (c *Client) DidClose(uri string) error {
+	return c.Notify("textDocument/didClose", struct {
+		TextDocument TextDocumentIdentifier `json:"textDocument"`
+	}{
+		TextDocument: TextDocumentIdentifier{URI: uri},
+	})
+}
+
+// textDocumentPositionParams is the params shape shared by textDocument/completion,
+// textDocument/hover, and textDocument/definition.
+type textDocumentPositionParams struct {
+	TextDocument TextDocumentIdentifier `json:"textDocument"`
+	Position     Position               `json:"position"`
+}
+
+// Completion requests completion candidates at pos in uri.
+func (c *Client) Completion(ctx context.Context, uri string, pos Position) (*CompletionList, error) {
+	result, err := c.Call(ctx, "textDocument/completion", textDocumentPositionParams{
+		TextDocument: TextDocumentIdentifier{URI: uri},
+		Position:     pos,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var list CompletionList
+	if err := json.Unmarshal(result, &list); err != nil {
+		return nil, errors.Wrapf(err, "unmarshaling CompletionList")
+	}
+	return &list, nil
+}
+
+// Hover requests the documentation or type information to show for pos in uri.
+func (c *Client) Hover(ctx context.Context, uri string, pos Position) (*Hover, error) {
+	result, err := c.Call(ctx, "textDocument/hover", textDocumentPositionParams{
+		TextDocument: TextDocumentIdentifier{URI: uri},
+		Position:     pos,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var hover Hover
+	if err := json.Unmarshal(result, &hover); err != nil {
+		return nil, errors.Wrapf(err, "unmarshaling Hover")
+	}
+	return &hover, nil
+}
+
+// Definition requests the location(s) where the symbol at pos in uri is defined.
+func (c *Client) Definition(ctx context.Context, uri string, pos Position) ([]Range, error) {
+	result, err := c.Call(ctx, "textDocument/definition", textDocumentPositionParams{
+		TextDocument: TextDocumentIdentifier{URI: uri},
+		Position:     pos,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var locations []struct {
+		URI   string `json:"uri"`
+		Range Range  `json:"range"`
+	}
+	if err := json.Unmarshal(result, &locations); err != nil {
+		return nil, errors.Wrapf(err, "unmarshaling definition locations")
+	}
+
+	ranges := make([]Range, len(locations))
+	for i, loc := range locations {
+		ranges[i] = loc.Range
+	}
+	return ranges, nil
+}
+
+// Formatting requests the edits that would reformat the whole document at uri, returned as a
+// set of TextEdits to splice in rather than a full-document replacement (unlike
+// input.RunFilterCommand, which replaces the whole filtered region with a command's output).
+func (c *Client) Formatting(ctx context.Context, uri string, tabSize int, insertSpaces bool) ([]TextEdit, error) {
+	result, err := c.Call(ctx, "textDocument/formatting", struct {
+		TextDocument TextDocumentIdentifier `json:"textDocument"`
+		Options      formattingOptions      `json:"options"`
+	}{
+		TextDocument: TextDocumentIdentifier{URI: uri},
+		Options:      formattingOptions{TabSize: tabSize, InsertSpaces: insertSpaces},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var edits []TextEdit
+	if err := json.Unmarshal(result, &edits); err != nil {
+		return nil, errors.Wrapf(err, "unmarshaling formatting edits")
+	}
+	return edits, nil
+}
+
+type formattingOptions struct {
+	TabSize      int  `json:"tabSize"`
+	InsertSpaces bool `json:"insertSpaces"`
+}
@@ -0,0 +1,84 @@
+package lsp
+
+// Position is a zero-indexed (line, UTF-16 code unit) position, per the LSP specification.
+type Position struct {
+	Line      uint64 `json:"line"`
+	Character uint64 `json:"character"`
+}
+
+// Range is a half-open [Start, End) span of text.
+type Range struct {
+	Start Position `json:"start"`
+	End   Position `json:"end"`
+}
+
+// TextEdit replaces the text in Range with NewText.
+type TextEdit struct {
+	Range   Range  `json:"range"`
+	NewText string `json:"newText"`
+}
+
+// DiagnosticSeverity mirrors the LSP DiagnosticSeverity enum.
+type DiagnosticSeverity int
+
+const (
+	DiagnosticSeverityError       DiagnosticSeverity = 1
+	DiagnosticSeverityWarning     DiagnosticSeverity = 2
+	DiagnosticSeverityInformation DiagnosticSeverity = 3
+	DiagnosticSeverityHint        DiagnosticSeverity = 4
+)
+
+// Diagnostic is one entry from a textDocument/publishDiagnostics notification.
+type Diagnostic struct {
+	Range    Range              `json:"range"`
+	Severity DiagnosticSeverity `json:"severity"`
+	Message  string             `json:"message"`
+	Source   string             `json:"source"`
+}
+
+// PublishDiagnosticsParams is the params object of a textDocument/publishDiagnostics
+// notification.
+type PublishDiagnosticsParams struct {
+	URI         string       `json:"uri"`
+	Diagnostics []Diagnostic `json:"diagnostics"`
+}
+
+// CompletionItem is one entry in a completion list.
+type CompletionItem struct {
+	Label               string     `json:"label"`
+	Detail              string     `json:"detail"`
+	Documentation       string     `json:"documentation"`
+	AdditionalTextEdits []TextEdit `json:"additionalTextEdits"`
+}
+
+// CompletionList is the result of a textDocument/completion request.
+type CompletionList struct {
+	IsIncomplete bool             `json:"isIncomplete"`
+	Items        []CompletionItem `json:"items"`
+}
+
+// Hover is the result of a textDocument/hover request.
+type Hover struct {
+	Contents string `json:"contents"`
+	Range    *Range `json:"range,omitempty"`
+}
+
+// TextDocumentIdentifier names the document a request or notification applies to.
+type TextDocumentIdentifier struct {
+	URI string `json:"uri"`
+}
+
+// VersionedTextDocumentIdentifier is a TextDocumentIdentifier with the document's edit
+// version, required by textDocument/didChange so the server can detect dropped notifications.
+type VersionedTextDocumentIdentifier struct {
+	URI     string `json:"uri"`
+	Version int    `json:"version"`
+}
+
+// TextDocumentContentChangeEvent describes one incremental edit for textDocument/didChange.
+// Range nil means "replace the whole document", used only as a fallback when incremental
+// sync isn't possible (e.g. right after Initialize, before any edit history exists).
+type TextDocumentContentChangeEvent struct {
+	Range *Range `json:"range,omitempty"`
+	Text  string `json:"text"`
+}
@@ -0,0 +1,32 @@
+package lsp
+
+import (
+	"os"
+	"path/filepath"
+)
+
+// RootDirForFile walks up from the directory containing path, returning the first ancestor
+// directory (inclusive) that contains any of markers (e.g. ".git", "go.mod"), so a language
+// server can be pointed at the project root instead of the single file being edited. If no
+// ancestor contains a marker, it returns the directory containing path.
+func RootDirForFile(path string, markers []string) (string, error) {
+	absPath, err := filepath.Abs(path)
+	if err != nil {
+		return "", err
+	}
+
+	dir := filepath.Dir(absPath)
+	for {
+		for _, marker := range markers {
+			if _, err := os.Stat(filepath.Join(dir, marker)); err == nil {
+				return dir, nil
+			}
+		}
+
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return filepath.Dir(absPath), nil
+		}
+		dir = parent
+	}
+}
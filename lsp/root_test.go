@@ -0,0 +1,35 @@
+package lsp
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRootDirForFile(t *testing.T) {
+	root := t.TempDir()
+	require.NoError(t, os.Mkdir(filepath.Join(root, ".git"), 0755))
+
+	pkgDir := filepath.Join(root, "pkg", "sub")
+	require.NoError(t, os.MkdirAll(pkgDir, 0755))
+
+	filePath := filepath.Join(pkgDir, "file.go")
+	require.NoError(t, os.WriteFile(filePath, []byte("package sub"), 0644))
+
+	got, err := RootDirForFile(filePath, []string{".git", "go.mod"})
+	require.NoError(t, err)
+	assert.Equal(t, root, got)
+}
+
+func TestRootDirForFileNoMarkerFallsBackToFileDir(t *testing.T) {
+	dir := t.TempDir()
+	filePath := filepath.Join(dir, "file.go")
+	require.NoError(t, os.WriteFile(filePath, []byte("package main"), 0644))
+
+	got, err := RootDirForFile(filePath, []string{".git", "go.mod"})
+	require.NoError(t, err)
+	assert.Equal(t, dir, got)
+}
@@ -0,0 +1,88 @@
+package plugin
+
+import (
+	"log"
+
+	lua "github.com/yuin/gopher-lua"
+
+	"github.com/aretext/aretext/exec"
+	"github.com/aretext/aretext/state"
+)
+
+// editorStateUD is the Lua global under which the *state.EditorState active for the current
+// command or hook call is stashed, so aretext.mutate.* functions (which take no EditorState
+// argument of their own) can reach it.
+const editorStateUD = "__aretext_editor_state"
+
+// registerAPI installs the `aretext` global table that plugin scripts call into:
+// aretext.register_command, aretext.register_hook, and the aretext.mutate.* namespace.
+func registerAPI(l *lua.LState, m *Manager) {
+	aretext := l.NewTable()
+
+	l.SetField(aretext, "register_command", l.NewFunction(func(l *lua.LState) int {
+		name := l.CheckString(1)
+		fn := l.CheckFunction(2)
+		m.commands = append(m.commands, Command{Name: name, Action: bridgeMutator(l, fn)})
+		return 0
+	}))
+
+	l.SetField(aretext, "register_hook", l.NewFunction(func(l *lua.LState) int {
+		hookName := l.CheckString(1)
+		fn := l.CheckFunction(2)
+		m.hooks[hookName] = append(m.hooks[hookName], hookEntry{l: l, fn: fn})
+		return 0
+	}))
+
+	l.SetField(aretext, "mutate", newMutateTable(l))
+	l.SetGlobal("aretext", aretext)
+}
+
+// bridgeMutator wraps a Lua function registered via register_command as an exec.Mutator: it
+// stashes s where aretext.mutate.* calls can find it, then runs fn.
+func bridgeMutator(l *lua.LState, fn *lua.LFunction) exec.Mutator {
+	return func(s *state.EditorState) {
+		l.SetGlobal(editorStateUD, stateUserData(l, s))
+		if err := l.CallByParam(lua.P{Fn: fn, NRet: 0, Protect: true}); err != nil {
+			log.Printf("plugin command error: %v", err)
+		}
+	}
+}
+
+func stateUserData(l *lua.LState, s *state.EditorState) *lua.LUserData {
+	ud := l.NewUserData()
+	ud.Value = s
+	return ud
+}
+
+func currentEditorState(l *lua.LState) *state.EditorState {
+	ud, ok := l.GetGlobal(editorStateUD).(*lua.LUserData)
+	if !ok {
+		return nil
+	}
+	s, _ := ud.Value.(*state.EditorState)
+	return s
+}
+
+// newMutateTable builds the aretext.mutate namespace, exposing a subset of exec.Mutator
+// constructors as Lua functions that apply immediately to the editor state stashed by
+// bridgeMutator or Manager.RunHook.
+func newMutateTable(l *lua.LState) *lua.LTable {
+	mutate := l.NewTable()
+
+	l.SetField(mutate, "insert_text", l.NewFunction(func(l *lua.LState) int {
+		text := l.CheckString(1)
+		if s := currentEditorState(l); s != nil {
+			exec.NewInsertTextMutator(text)(s)
+		}
+		return 0
+	}))
+
+	l.SetField(mutate, "save_document", l.NewFunction(func(l *lua.LState) int {
+		if s := currentEditorState(l); s != nil {
+			exec.NewSaveDocumentMutator(false)(s)
+		}
+		return 0
+	}))
+
+	return mutate
+}
@@ -0,0 +1,74 @@
+package plugin
+
+import (
+	"os"
+	osexec "os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// InstallFromGit clones the plugin at gitURL into a subdirectory of the manager's plugin
+// directory, named after the repository (the last path segment with any ".git" suffix
+// stripped). The clone is picked up the next time LoadAll runs, via its init.lua entrypoint.
+func (m *Manager) InstallFromGit(gitURL string) error {
+	name := pluginNameFromURL(gitURL)
+	dest := filepath.Join(m.dir, name)
+	if _, err := os.Stat(dest); err == nil {
+		return errors.Errorf("plugin %s is already installed", name)
+	}
+
+	if err := os.MkdirAll(m.dir, 0755); err != nil {
+		return errors.Wrapf(err, "os.MkdirAll")
+	}
+
+	cmd := osexec.Command("git", "clone", "--depth", "1", gitURL, dest)
+	if output, err := cmd.CombinedOutput(); err != nil {
+		return errors.Wrapf(err, "git clone: %s", output)
+	}
+	return nil
+}
+
+// UpdatePlugins runs `git pull` in every installed plugin's directory, continuing past any
+// that fail so one broken plugin doesn't block the rest from updating. It returns one error
+// per directory that failed to update.
+func (m *Manager) UpdatePlugins() []error {
+	entries, err := os.ReadDir(m.dir)
+	if os.IsNotExist(err) {
+		return nil
+	} else if err != nil {
+		return []error{errors.Wrapf(err, "os.ReadDir")}
+	}
+
+	var errs []error
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+		pluginDir := filepath.Join(m.dir, entry.Name())
+		cmd := osexec.Command("git", "-C", pluginDir, "pull", "--ff-only")
+		if output, err := cmd.CombinedOutput(); err != nil {
+			errs = append(errs, errors.Wrapf(err, "update %s: %s", entry.Name(), output))
+		}
+	}
+	return errs
+}
+
+// RemovePlugin deletes the installed plugin directory named name. Removing a plugin that was
+// loaded this session takes effect on the next restart, the same as installing a new one does.
+func (m *Manager) RemovePlugin(name string) error {
+	dest := filepath.Join(m.dir, name)
+	if err := os.RemoveAll(dest); err != nil {
+		return errors.Wrapf(err, "os.RemoveAll")
+	}
+	return nil
+}
+
+func pluginNameFromURL(gitURL string) string {
+	name := gitURL
+	if idx := strings.LastIndex(name, "/"); idx >= 0 {
+		name = name[idx+1:]
+	}
+	return strings.TrimSuffix(name, ".git")
+}
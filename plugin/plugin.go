@@ -0,0 +1,136 @@
+// Package plugin implements a Lua scripting layer so users can extend aretext without
+// recompiling: registering commands and hooking into buffer lifecycle events from
+// ~/.config/aretext/plugins/*.lua. The aretext table exposed to scripts has no function for
+// binding keys. This is the dynamic counterpart to config.RuleSet,
+// which lets users customize behavior declaratively; plugins let them script it instead.
+package plugin
+
+import (
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+
+	lua "github.com/yuin/gopher-lua"
+	"github.com/pkg/errors"
+
+	"github.com/aretext/aretext/exec"
+	"github.com/aretext/aretext/state"
+)
+
+// Hook names recognized by aretext.register_hook.
+const (
+	HookPreInsert  = "pre_insert"
+	HookPostInsert = "post_insert"
+	HookPreSave    = "pre_save"
+	HookPostSave   = "post_save"
+	HookPreLoad    = "pre_load"
+	HookPostLoad   = "post_load"
+)
+
+// Command is a user-defined menu command registered by a plugin via aretext.register_command.
+type Command struct {
+	Name   string
+	Action exec.Mutator
+}
+
+type hookEntry struct {
+	l  *lua.LState
+	fn *lua.LFunction
+}
+
+// Manager loads Lua plugins from a directory and dispatches the commands and hooks they
+// register. The zero value is not usable; construct one with NewManager.
+type Manager struct {
+	dir      string
+	states   []*lua.LState
+	commands []Command
+	hooks    map[string][]hookEntry
+}
+
+// NewManager returns a Manager that will load plugins from dir (typically the directory
+// returned by DefaultPluginDir).
+func NewManager(dir string) *Manager {
+	return &Manager{
+		dir:   dir,
+		hooks: make(map[string][]hookEntry),
+	}
+}
+
+// DefaultPluginDir returns ~/.config/aretext/plugins, alongside config's own default config
+// path.
+func DefaultPluginDir() (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", errors.Wrapf(err, "os.UserHomeDir")
+	}
+	return filepath.Join(home, ".config", "aretext", "plugins"), nil
+}
+
+// LoadAll discovers and runs every installed plugin: a standalone "*.lua" file directly in the
+// plugin directory, or a subdirectory (as created by InstallFromGit) containing an "init.lua"
+// entrypoint. A missing plugin directory is not an error, since most users have none installed.
+func (m *Manager) LoadAll() error {
+	entries, err := os.ReadDir(m.dir)
+	if os.IsNotExist(err) {
+		return nil
+	} else if err != nil {
+		return errors.Wrapf(err, "os.ReadDir")
+	}
+
+	var paths []string
+	for _, entry := range entries {
+		switch {
+		case !entry.IsDir() && filepath.Ext(entry.Name()) == ".lua":
+			paths = append(paths, filepath.Join(m.dir, entry.Name()))
+		case entry.IsDir():
+			initPath := filepath.Join(m.dir, entry.Name(), "init.lua")
+			if _, err := os.Stat(initPath); err == nil {
+				paths = append(paths, initPath)
+			}
+		}
+	}
+	sort.Strings(paths)
+
+	for _, path := range paths {
+		if err := m.loadFile(path); err != nil {
+			return errors.Wrapf(err, "loading plugin %s", path)
+		}
+	}
+	return nil
+}
+
+func (m *Manager) loadFile(path string) error {
+	l := lua.NewState()
+	registerAPI(l, m)
+	if err := l.DoFile(path); err != nil {
+		l.Close()
+		return err
+	}
+	m.states = append(m.states, l)
+	return nil
+}
+
+// Commands returns every command a loaded plugin registered, in registration order, so
+// input.commandMenuItems can append them to the command menu.
+func (m *Manager) Commands() []Command {
+	return m.commands
+}
+
+// RunHook invokes every function a plugin registered for hookName against s, in registration
+// order. A hook function that errors is logged and skipped rather than aborting the edit.
+func (m *Manager) RunHook(hookName string, s *state.EditorState) {
+	for _, entry := range m.hooks[hookName] {
+		entry.l.SetGlobal(editorStateUD, stateUserData(entry.l, s))
+		if err := entry.l.CallByParam(lua.P{Fn: entry.fn, NRet: 0, Protect: true}); err != nil {
+			log.Printf("plugin hook %q failed: %v", hookName, err)
+		}
+	}
+}
+
+// Close releases every Lua VM the manager started.
+func (m *Manager) Close() {
+	for _, l := range m.states {
+		l.Close()
+	}
+}
@@ -0,0 +1,146 @@
+// Package session persists per-file editing state - cursor position, visual-mode selection
+// anchor, last search query, and viewport top line - across editing sessions, so reopening a
+// file can restore roughly where the user left off.
+package session
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// Entry is the persisted state for a single file.
+type Entry struct {
+	CursorPos       uint64    `json:"cursorPos"`
+	SelectionAnchor uint64    `json:"selectionAnchor,omitempty"`
+	LastSearchQuery string    `json:"lastSearchQuery,omitempty"`
+	ViewTopLine     uint64    `json:"viewTopLine"`
+	FileHash        string    `json:"fileHash"`
+	ModTime         time.Time `json:"modTime"`
+}
+
+// Store maps an absolute file path to its persisted Entry. A Store is safe for concurrent use.
+type Store struct {
+	path    string
+	mu      sync.Mutex
+	entries map[string]Entry
+}
+
+// DefaultPath returns $XDG_STATE_HOME/aretext/sessions.json, falling back to
+// ~/.local/state/aretext/sessions.json if XDG_STATE_HOME isn't set.
+func DefaultPath() (string, error) {
+	stateHome := os.Getenv("XDG_STATE_HOME")
+	if stateHome == "" {
+		homeDir, err := os.UserHomeDir()
+		if err != nil {
+			return "", errors.Wrapf(err, "os.UserHomeDir")
+		}
+		stateHome = filepath.Join(homeDir, ".local", "state")
+	}
+	return filepath.Join(stateHome, "aretext", "sessions.json"), nil
+}
+
+// Load reads the session store from path. A missing file isn't an error - it produces an
+// empty store, so a first run doesn't need anything pre-created.
+func Load(path string) (*Store, error) {
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return &Store{path: path, entries: make(map[string]Entry)}, nil
+	} else if err != nil {
+		return nil, errors.Wrapf(err, "os.ReadFile")
+	}
+
+	entries := make(map[string]Entry)
+	if err := json.Unmarshal(data, &entries); err != nil {
+		return nil, errors.Wrapf(err, "json.Unmarshal")
+	}
+
+	return &Store{path: path, entries: entries}, nil
+}
+
+// EntryForFile returns the stored Entry for the absolute path absPath and whether it's still
+// valid. An entry is valid only if absPath's current content hash matches the hash recorded
+// when the entry was saved; a mismatch means the file changed outside this store's view of
+// it (e.g. an external edit), so the stored position can no longer be trusted.
+func (st *Store) EntryForFile(absPath string) (Entry, bool) {
+	st.mu.Lock()
+	entry, ok := st.entries[absPath]
+	st.mu.Unlock()
+	if !ok {
+		return Entry{}, false
+	}
+
+	hash, err := hashFile(absPath)
+	if err != nil || hash != entry.FileHash {
+		return Entry{}, false
+	}
+
+	return entry, true
+}
+
+// SetEntryForFile records entry as the session state for absPath, stamping it with absPath's
+// current content hash and the current time so a later EntryForFile call can detect whether
+// the file has changed since.
+func (st *Store) SetEntryForFile(absPath string, entry Entry) error {
+	hash, err := hashFile(absPath)
+	if err != nil {
+		return err
+	}
+	entry.FileHash = hash
+	entry.ModTime = time.Now()
+
+	st.mu.Lock()
+	defer st.mu.Unlock()
+	st.entries[absPath] = entry
+	return nil
+}
+
+// ClearEntryForFile removes any stored session state for absPath.
+func (st *Store) ClearEntryForFile(absPath string) {
+	st.mu.Lock()
+	defer st.mu.Unlock()
+	delete(st.entries, absPath)
+}
+
+// Save writes the store back to its path, creating the parent directory if necessary.
+func (st *Store) Save() error {
+	st.mu.Lock()
+	defer st.mu.Unlock()
+
+	data, err := json.MarshalIndent(st.entries, "", "  ")
+	if err != nil {
+		return errors.Wrapf(err, "json.MarshalIndent")
+	}
+
+	if err := os.MkdirAll(filepath.Dir(st.path), 0755); err != nil {
+		return errors.Wrapf(err, "os.MkdirAll")
+	}
+
+	if err := os.WriteFile(st.path, data, 0644); err != nil {
+		return errors.Wrapf(err, "os.WriteFile")
+	}
+
+	return nil
+}
+
+func hashFile(path string) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", errors.Wrapf(err, "os.Open")
+	}
+	defer f.Close()
+
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return "", errors.Wrapf(err, "io.Copy")
+	}
+
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
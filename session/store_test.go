@@ -0,0 +1,80 @@
+package session
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStoreSetAndLoadEntry(t *testing.T) {
+	dir := t.TempDir()
+
+	filePath := filepath.Join(dir, "doc.txt")
+	require.NoError(t, os.WriteFile(filePath, []byte("hello world"), 0644))
+
+	storePath := filepath.Join(dir, "sessions.json")
+	store, err := Load(storePath)
+	require.NoError(t, err)
+
+	_, ok := store.EntryForFile(filePath)
+	assert.False(t, ok, "should have no entry before one is set")
+
+	entry := Entry{CursorPos: 3, ViewTopLine: 1}
+	require.NoError(t, store.SetEntryForFile(filePath, entry))
+
+	got, ok := store.EntryForFile(filePath)
+	require.True(t, ok)
+	assert.Equal(t, uint64(3), got.CursorPos)
+	assert.Equal(t, uint64(1), got.ViewTopLine)
+
+	require.NoError(t, store.Save())
+
+	reloaded, err := Load(storePath)
+	require.NoError(t, err)
+	got, ok = reloaded.EntryForFile(filePath)
+	require.True(t, ok)
+	assert.Equal(t, uint64(3), got.CursorPos)
+}
+
+func TestStoreEntryInvalidatedByExternalEdit(t *testing.T) {
+	dir := t.TempDir()
+
+	filePath := filepath.Join(dir, "doc.txt")
+	require.NoError(t, os.WriteFile(filePath, []byte("hello world"), 0644))
+
+	store, err := Load(filepath.Join(dir, "sessions.json"))
+	require.NoError(t, err)
+
+	require.NoError(t, store.SetEntryForFile(filePath, Entry{CursorPos: 5}))
+
+	require.NoError(t, os.WriteFile(filePath, []byte("something else entirely"), 0644))
+
+	_, ok := store.EntryForFile(filePath)
+	assert.False(t, ok, "entry should be invalidated once the file's content changes")
+}
+
+func TestStoreClearEntryForFile(t *testing.T) {
+	dir := t.TempDir()
+
+	filePath := filepath.Join(dir, "doc.txt")
+	require.NoError(t, os.WriteFile(filePath, []byte("hello world"), 0644))
+
+	store, err := Load(filepath.Join(dir, "sessions.json"))
+	require.NoError(t, err)
+
+	require.NoError(t, store.SetEntryForFile(filePath, Entry{CursorPos: 5}))
+	store.ClearEntryForFile(filePath)
+
+	_, ok := store.EntryForFile(filePath)
+	assert.False(t, ok)
+}
+
+func TestLoadMissingFileReturnsEmptyStore(t *testing.T) {
+	dir := t.TempDir()
+	store, err := Load(filepath.Join(dir, "does-not-exist", "sessions.json"))
+	require.NoError(t, err)
+	assert.Empty(t, store.entries)
+}
@@ -0,0 +1,31 @@
+//go:build ignore
+
+// Command assets_generate walks runtime/ (syntax definitions, color schemes, and help files
+// referenced by input.commandMenuItems) and emits assets/assets_vfsdata.go: a compiled-in
+// http.FileSystem so aretext ships as a single binary. Run it with:
+//
+//	go generate ./assets
+//
+// The go:generate directive in assets/assets.go invokes this file with its working directory
+// set to assets/ (go generate always runs in the directory of the file declaring the
+// directive), so the paths below are relative to assets/, not the repo root.
+package main
+
+import (
+	"log"
+	"net/http"
+
+	"github.com/shurcooL/vfsgen"
+)
+
+func main() {
+	err := vfsgen.Generate(http.Dir("../runtime"), vfsgen.Options{
+		PackageName:  "assets",
+		BuildTags:    "!dev",
+		VariableName: "FileSystem",
+		Filename:     "assets_vfsdata.go",
+	})
+	if err != nil {
+		log.Fatalf("vfsgen.Generate: %v", err)
+	}
+}